@@ -0,0 +1,70 @@
+// Package cache defines the cache used by the SpaceTraders client and its default in-memory
+// implementation. A custom implementation can be passed in client.ClientOptions.Cache.
+package cache
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultExpiration uses the cache's default expiration, which is NoExpiration for Memory
+	DefaultExpiration time.Duration = 0
+	// NoExpiration keeps an item until it is deleted, evicted or the cache is cleared
+	NoExpiration time.Duration = -1
+)
+
+// NamespaceSeparator separates a namespace from the keys within it, as in "responses:GET /systems"
+const NamespaceSeparator = ":"
+
+// Entry is an item in a cache
+type Entry struct {
+	Key   string
+	Value any
+	// Expires is when the item expires, zero if it never does
+	Expires time.Time
+}
+
+// Cache stores values under string keys, each with its own expiration. Implementations
+// must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key, and false if it is missing or expired
+	Get(key string) (any, bool)
+	// Set stores value under key for ttl, which may be DefaultExpiration or NoExpiration
+	Set(key string, value any, ttl time.Duration)
+	// Delete removes the item stored under key
+	Delete(key string)
+	// DeletePrefix removes every item whose key starts with prefix, returning how many were removed
+	DeletePrefix(prefix string) int
+	// Clear removes every item
+	Clear()
+	// Size returns the number of items
+	Size() int
+	// Entries returns the unexpired items for which match returns true, or every
+	// unexpired item if match is nil, sorted by key
+	Entries(match func(Entry) bool) []Entry
+	// Namespace returns a view of the items whose keys start with name and
+	// NamespaceSeparator. Keys passed to and returned by the view omit that prefix.
+	Namespace(name string) Cache
+	// Dump writes a snapshot of the unexpired items, see WriteSnapshot
+	Dump(w io.Writer) error
+	// Restore adds the items of a snapshot written by Dump, see ReadSnapshot
+	Restore(r io.Reader) error
+}
+
+// HasPrefix returns an Entries filter matching keys that start with prefix
+func HasPrefix(prefix string) func(Entry) bool {
+	return func(entry Entry) bool {
+		return strings.HasPrefix(entry.Key, prefix)
+	}
+}
+
+// ttlUntil returns the ttl keeping an item until expires, and false if it has already passed
+func ttlUntil(expires time.Time) (time.Duration, bool) {
+	if expires.IsZero() {
+		return NoExpiration, true
+	}
+	ttl := time.Until(expires)
+	return ttl, ttl > 0
+}
@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryEntries(t *testing.T) {
+	c := NewMemory(MemoryOptions{})
+	defer c.Close()
+
+	c.Set("b", 2, time.Hour)
+	c.Set("a", 1, NoExpiration)
+	c.Set("expired", 3, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	entries := c.Entries(nil)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Key, "entries are sorted by key")
+	assert.True(t, entries[0].Expires.IsZero())
+	assert.Equal(t, "b", entries[1].Key)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), entries[1].Expires, time.Second)
+
+	filtered := c.Entries(func(entry Entry) bool { return entry.Value == 2 })
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "b", filtered[0].Key)
+}
+
+func TestMemoryDeletePrefix(t *testing.T) {
+	c := NewMemory(MemoryOptions{})
+	defer c.Close()
+
+	c.Set("responses:GET /systems/X1", "system", NoExpiration)
+	c.Set("responses:GET /systems/X1/waypoints", "waypoints", NoExpiration)
+	c.Set("responses:GET /systems/X2", "system", NoExpiration)
+
+	assert.Equal(t, 2, c.DeletePrefix("responses:GET /systems/X1"))
+	assert.Equal(t, 1, c.Size())
+	assert.Len(t, c.Entries(HasPrefix("responses:")), 1)
+}
+
+func TestNamespace(t *testing.T) {
+	c := NewMemory(MemoryOptions{})
+	defer c.Close()
+
+	graphs := c.Namespace("graphs")
+	graphs.Set("X1", "graph", NoExpiration)
+	c.Set("X1", "other", NoExpiration)
+
+	value, found := graphs.Get("X1")
+	require.True(t, found)
+	assert.Equal(t, "graph", value)
+	value, _ = c.Get("graphs:X1")
+	assert.Equal(t, "graph", value)
+
+	nested := graphs.Namespace("fast")
+	nested.Set("X2", "graph", NoExpiration)
+	_, found = c.Get("graphs:fast:X2")
+	assert.True(t, found)
+
+	entries := graphs.Entries(nil)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "X1", entries[0].Key)
+	assert.Equal(t, "fast:X2", entries[1].Key)
+	assert.Equal(t, 2, graphs.Size())
+
+	graphs.Clear()
+	assert.Equal(t, 0, graphs.Size())
+	assert.Equal(t, 1, c.Size(), "clearing a namespace keeps other keys")
+}
+
+func TestDumpRestore(t *testing.T) {
+	c := NewMemory(MemoryOptions{})
+	defer c.Close()
+
+	c.Set("forever", map[string]int{"count": 1}, NoExpiration)
+	c.Set("hour", "value", time.Hour)
+	c.Set("expired", "value", time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	var snapshot bytes.Buffer
+	require.NoError(t, c.Dump(&snapshot))
+
+	restored := NewMemory(MemoryOptions{})
+	defer restored.Close()
+	require.NoError(t, restored.Restore(&snapshot))
+
+	entries := restored.Entries(nil)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "forever", entries[0].Key)
+	assert.True(t, entries[0].Expires.IsZero())
+	assert.JSONEq(t, `{"count":1}`, string(entries[0].Value.(json.RawMessage)))
+	assert.Equal(t, "hour", entries[1].Key)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), entries[1].Expires, time.Second)
+}
+
+func TestRestoreNamespace(t *testing.T) {
+	c := NewMemory(MemoryOptions{})
+	defer c.Close()
+	c.Namespace("responses").Set("GET /systems", "systems", NoExpiration)
+	c.Set("other", "value", NoExpiration)
+
+	var snapshot bytes.Buffer
+	require.NoError(t, c.Namespace("responses").Dump(&snapshot))
+
+	restored := NewMemory(MemoryOptions{})
+	defer restored.Close()
+	require.NoError(t, restored.Namespace("responses").Restore(&snapshot))
+
+	assert.Equal(t, 1, restored.Size())
+	_, found := restored.Get("responses:GET /systems")
+	assert.True(t, found)
+}
+
+func TestReadSnapshotVersion(t *testing.T) {
+	_, err := ReadSnapshot(bytes.NewBufferString(`{"version":2,"entries":[]}`))
+	assert.Error(t, err)
+}
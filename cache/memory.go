@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	internal "github.com/jjkirkpatrick/spacetraders-client/internal/cache"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// MemoryOptions configures a Memory cache
+type MemoryOptions struct {
+	// MaxEntries bounds the number of items, evicting the least recently used first
+	// (default: 0, unbounded)
+	MaxEntries int
+	// DefaultTTL is the expiration used for DefaultExpiration (default: NoExpiration)
+	DefaultTTL time.Duration
+	// JanitorInterval is how often expired items are removed in the background
+	// (default: 0, expired items are only removed when read or evicted)
+	JanitorInterval time.Duration
+}
+
+// Stats are the counters of a Memory cache since it was created
+type Stats = internal.Stats
+
+// Memory is the default Cache, an in-memory LRU cache
+type Memory struct {
+	cache *internal.Cache[string, any]
+}
+
+var _ Cache = (*Memory)(nil)
+
+// NewMemory creates an in-memory cache. Call Close to stop its janitor.
+func NewMemory(options MemoryOptions) *Memory {
+	return &Memory{cache: internal.New(internal.Options[string, any]{
+		MaxEntries:      options.MaxEntries,
+		DefaultTTL:      options.DefaultTTL,
+		JanitorInterval: options.JanitorInterval,
+	})}
+}
+
+func (m *Memory) Get(key string) (any, bool) {
+	return m.cache.Get(key)
+}
+
+func (m *Memory) Set(key string, value any, ttl time.Duration) {
+	m.cache.Set(key, value, ttl)
+}
+
+func (m *Memory) Delete(key string) {
+	m.cache.Delete(key)
+}
+
+func (m *Memory) DeletePrefix(prefix string) int {
+	return m.cache.DeleteFunc(func(key string, _ any) bool {
+		return strings.HasPrefix(key, prefix)
+	})
+}
+
+func (m *Memory) Clear() {
+	m.cache.Clear()
+}
+
+func (m *Memory) Size() int {
+	return m.cache.Size()
+}
+
+func (m *Memory) Entries(match func(Entry) bool) []Entry {
+	var entries []Entry
+	m.cache.Range(func(key string, value any, expires time.Time) bool {
+		entry := Entry{Key: key, Value: value, Expires: expires}
+		if match == nil || match(entry) {
+			entries = append(entries, entry)
+		}
+		return true
+	})
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries
+}
+
+func (m *Memory) Namespace(name string) Cache {
+	return Namespaced(m, name)
+}
+
+func (m *Memory) Dump(w io.Writer) error {
+	return WriteSnapshot(w, m.Entries(nil))
+}
+
+func (m *Memory) Restore(r io.Reader) error {
+	return restore(m, r)
+}
+
+// Stats returns the cache's hit, miss and eviction counters
+func (m *Memory) Stats() Stats {
+	return m.cache.Stats()
+}
+
+// RegisterMetrics reports the cache's counters, entries and size as observable metrics
+func (m *Memory) RegisterMetrics(meter metric.Meter, attrs ...attribute.KeyValue) error {
+	return m.cache.RegisterMetrics(meter, attrs...)
+}
+
+// Close stops the janitor and unregisters the metrics
+func (m *Memory) Close() error {
+	return m.cache.Close()
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// namespace is a view of the items of a parent cache whose keys start with prefix
+type namespace struct {
+	parent Cache
+	prefix string
+}
+
+// Namespaced returns a view of the items of parent whose keys start with name and
+// NamespaceSeparator. Custom implementations can use it to implement Cache.Namespace.
+func Namespaced(parent Cache, name string) Cache {
+	return &namespace{parent: parent, prefix: name + NamespaceSeparator}
+}
+
+func (n *namespace) Get(key string) (any, bool) {
+	return n.parent.Get(n.prefix + key)
+}
+
+func (n *namespace) Set(key string, value any, ttl time.Duration) {
+	n.parent.Set(n.prefix+key, value, ttl)
+}
+
+func (n *namespace) Delete(key string) {
+	n.parent.Delete(n.prefix + key)
+}
+
+func (n *namespace) DeletePrefix(prefix string) int {
+	return n.parent.DeletePrefix(n.prefix + prefix)
+}
+
+func (n *namespace) Clear() {
+	n.parent.DeletePrefix(n.prefix)
+}
+
+func (n *namespace) Size() int {
+	return len(n.parent.Entries(HasPrefix(n.prefix)))
+}
+
+func (n *namespace) Entries(match func(Entry) bool) []Entry {
+	entries := n.parent.Entries(HasPrefix(n.prefix))
+
+	matched := entries[:0]
+	for _, entry := range entries {
+		entry.Key = strings.TrimPrefix(entry.Key, n.prefix)
+		if match == nil || match(entry) {
+			matched = append(matched, entry)
+		}
+	}
+	return matched
+}
+
+func (n *namespace) Namespace(name string) Cache {
+	return Namespaced(n, name)
+}
+
+func (n *namespace) Dump(w io.Writer) error {
+	return WriteSnapshot(w, n.Entries(nil))
+}
+
+func (n *namespace) Restore(r io.Reader) error {
+	return restore(n, r)
+}
@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// snapshotVersion is the version of the snapshot format written by WriteSnapshot
+const snapshotVersion = 1
+
+type snapshot struct {
+	Version int             `json:"version"`
+	Entries []snapshotEntry `json:"entries"`
+}
+
+type snapshotEntry struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value"`
+	Expires *time.Time      `json:"expires,omitempty"`
+}
+
+// WriteSnapshot writes entries to w as JSON. Values are encoded with encoding/json, so
+// values that cannot be encoded fail the snapshot.
+func WriteSnapshot(w io.Writer, entries []Entry) error {
+	s := snapshot{Version: snapshotVersion, Entries: make([]snapshotEntry, 0, len(entries))}
+	for _, entry := range entries {
+		value, err := json.Marshal(entry.Value)
+		if err != nil {
+			return fmt.Errorf("failed to encode cache entry %q: %w", entry.Key, err)
+		}
+
+		encoded := snapshotEntry{Key: entry.Key, Value: value}
+		if !entry.Expires.IsZero() {
+			expires := entry.Expires
+			encoded.Expires = &expires
+		}
+		s.Entries = append(s.Entries, encoded)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(s); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads the entries written by WriteSnapshot, skipping those that have since
+// expired. Values are json.RawMessage, since their original types are not recorded.
+func ReadSnapshot(r io.Reader) ([]Entry, error) {
+	var s snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to read cache snapshot: %w", err)
+	}
+	if s.Version != snapshotVersion {
+		return nil, fmt.Errorf("unsupported cache snapshot version %d", s.Version)
+	}
+
+	entries := make([]Entry, 0, len(s.Entries))
+	for _, encoded := range s.Entries {
+		entry := Entry{Key: encoded.Key, Value: encoded.Value}
+		if encoded.Expires != nil {
+			entry.Expires = *encoded.Expires
+			if _, ok := ttlUntil(entry.Expires); !ok {
+				continue
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// restore sets the entries of a snapshot in c with their remaining expiration
+func restore(c Cache, r io.Reader) error {
+	entries, err := ReadSnapshot(r)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if ttl, ok := ttlUntil(entry.Expires); ok {
+			c.Set(entry.Key, entry.Value, ttl)
+		}
+	}
+	return nil
+}
@@ -2,13 +2,11 @@ package client
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
+	"strings"
 
 	"github.com/jjkirkpatrick/spacetraders-client/models"
-	"github.com/phuslu/log"
 )
 
 // RegisterRequest represents the request payload for registering a new agent
@@ -30,15 +28,95 @@ type RegisterResponse struct {
 	} `json:"data"`
 }
 
-// TokenFile represents the structure of the token file
-type TokenFile struct {
-	Tokens map[string]string `json:"tokens"`
+// AuthMode controls when a client authenticates
+type AuthMode string
+
+const (
+	// AuthEager loads or registers the agent's token in NewClient. This is the default.
+	AuthEager AuthMode = "eager"
+	// AuthLazy defers loading or registering the token until the first authenticated request,
+	// so a client can be created while the API is unreachable
+	AuthLazy AuthMode = "lazy"
+	// AuthAnonymous never authenticates. Only public endpoints such as systems, factions and
+	// public agents can be called, and authenticated requests fail without reaching the API.
+	AuthAnonymous AuthMode = "anonymous"
+)
+
+// requiresAuth reports whether a request needs the agent's token
+func requiresAuth(method, endpoint string) bool {
+	if endpoint == "/register" {
+		return false
+	}
+	return method != "GET" || strings.HasPrefix(endpoint, "/my/") || endpoint == "/my"
+}
+
+// Authenticate loads or registers the agent's token if the client has not done so yet.
+// Lazy clients call it before their first authenticated request.
+func (c *Client) Authenticate() error {
+	if apiErr := c.ensureAuthenticated(); apiErr != nil {
+		return apiErr
+	}
+	return nil
+}
+
+// ensureAuthenticated authenticates a lazy client once. A failed attempt is retried by the next request.
+func (c *Client) ensureAuthenticated() *models.APIError {
+	switch c.authMode {
+	case AuthAnonymous:
+		return &models.APIError{Code: 401, Message: "anonymous client cannot make authenticated requests"}
+	case AuthLazy:
+	default:
+		return nil
+	}
+
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
+	if c.authenticated {
+		return nil
+	}
+
+	if err := c.getOrRegisterToken(c.faction, c.AgentSymbol, c.email); err != nil {
+		c.Logger.Error("Lazy authentication failed", "error", err)
+		var apiErr *models.APIError
+		if errors.As(err, &apiErr) {
+			return apiErr
+		}
+		return &models.APIError{Code: 401, Message: err.Error()}
+	}
+
+	c.authenticated = true
+	return nil
 }
 
-// GetOrRegisterToken retrieves the token for the given symbol from the token file or registers a new agent if the token doesn't exist
+// GetOrRegisterToken retrieves the token for the given symbol from the token store or registers a new agent if the token doesn't exist
 func (c *Client) getOrRegisterToken(faction, symbol, email string) error {
 	c.Logger.Debug("Attempting to get or register token", "faction", faction, "symbol", symbol, "email", email)
 
+	if err := validateRegistration(faction, symbol); err != nil {
+		return err
+	}
+
+	store := c.tokens()
+
+	// Check if a token exists for the given symbol
+	entry, exists, err := store.Get(symbol)
+	if err != nil {
+		c.Logger.Error("Failed to get token from store", "symbol", symbol, "error", err)
+		return err
+	}
+
+	if exists && entry.Token != "" {
+		// Token found, set it in the client
+		c.setToken(entry.Token)
+		return nil
+	}
+
+	return c.registerAgent(faction, symbol, email)
+}
+
+// validateRegistration checks the faction and symbol an agent is registered with
+func validateRegistration(faction, symbol string) error {
 	if faction == "" || symbol == "" {
 		return fmt.Errorf("faction and symbol must be set")
 	}
@@ -54,25 +132,23 @@ func (c *Client) getOrRegisterToken(faction, symbol, email string) error {
 	if _, ok := validFactions[faction]; !ok {
 		return fmt.Errorf("invalid faction: %s", faction)
 	}
+	return nil
+}
 
-	// Check if a token exists for the given symbol
-	token, err := c.getTokenFromFile(symbol)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to get token from file")
-		return err
-	}
-
-	if token != "" {
-		// Token found, set it in the client
-		c.token = token
-		return nil
-	}
+// registerAgent registers a new agent with the account token, publishes its token and
+// stores it with the reset it belongs to
+func (c *Client) registerAgent(faction, symbol, email string) error {
+	store := c.tokens()
 
 	var registerResp RegisterResponse
 
-	accountToken, err := c.getTokenFromFile("account")
-	if err != nil {
-		return err
+	account := TokenEntry{Token: c.accountToken}
+	if account.Token == "" {
+		var err error
+		account, _, err = store.Get(AccountTokenKey)
+		if err != nil {
+			return err
+		}
 	}
 
 	// Token not found, register a new agent
@@ -82,122 +158,48 @@ func (c *Client) getOrRegisterToken(faction, symbol, email string) error {
 		Email:   email,
 	}
 
-	c.token = accountToken
+	// The account token is only sent with the registration, the client keeps its own
+	// token until the new one is published
+	c.redactor.AddSecret(account.Token)
+	ctx := context.WithValue(context.Background(), requestTokenKey{}, account.Token)
 
 	// Use executeRequest directly since requestQueue won't be initialized yet
-	apiErr := c.executeRequest(context.Background(), "POST", "/register", registerReq, nil, &registerResp)
+	apiErr := c.executeRequest(ctx, "POST", "/register", registerReq, nil, &registerResp)
 	if apiErr != nil {
 		return apiErr
 	}
 
-	// Update the token file with the new token
-	err = c.updateTokenFile(symbol, registerResp.Data.Token)
-	if err != nil {
-		return err
-	}
-
-	c.token = registerResp.Data.Token
-	return nil
-}
-
-// getTokenFromFile retrieves the token for the given symbol from the token file
-func (c *Client) getTokenFromFile(symbol string) (string, error) {
-	file, err := os.Open("tokens.json")
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Token file doesn't exist, create an empty one
-			err = c.createEmptyTokenFile()
-			if err != nil {
-				return "", err
-			}
-			return "", nil
-		}
-		return "", err
-	}
-	defer file.Close()
-
-	var tokenFile TokenFile
-	err = json.NewDecoder(file).Decode(&tokenFile)
-	if err != nil {
-		return "", err
-	}
-
-	token, exists := tokenFile.Tokens[symbol]
-	if !exists {
-		return "", nil // Token does not exist for the given symbol
-	}
-
-	return token, nil
-}
-
-// updateTokenFile updates the token file with the new token for the given symbol
-func (c *Client) updateTokenFile(symbol, token string) error {
-	log.Debug().Msgf("Updating token file with new token for symbol %s", symbol)
-
-	// Read the current contents of the file
-	fileContent, err := ioutil.ReadFile("tokens.json")
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Token file doesn't exist, create a new one with the current token
-			tokenFile := TokenFile{
-				Tokens: map[string]string{
-					symbol: token,
-				},
-			}
-			return c.writeTokenFile(tokenFile)
-		}
-		return err
-	}
-
-	var tokenFile TokenFile
-	err = json.Unmarshal(fileContent, &tokenFile)
-	if err != nil {
-		return err
-	}
-
-	// Update the token map with the new token
-	if tokenFile.Tokens == nil {
-		tokenFile.Tokens = make(map[string]string)
-	}
-	tokenFile.Tokens[symbol] = token
+	c.setToken(registerResp.Data.Token)
 
-	// Write the updated token file
-	return c.writeTokenFile(tokenFile)
+	// Store the new token with the reset it belongs to
+	return store.Set(symbol, TokenEntry{
+		Token:     registerResp.Data.Token,
+		ResetDate: c.currentResetDate(),
+	})
 }
 
-func (c *Client) writeTokenFile(tokenFile TokenFile) error {
-	file, err := os.OpenFile("tokens.json", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// requestTokenKey overrides the token sent with a request
+type requestTokenKey struct{}
 
-	encoder := json.NewEncoder(file)
-	encoder.SetIndent("", "  ")
-	err = encoder.Encode(tokenFile)
-	if err != nil {
-		return err
+// tokens returns the configured token store, or the default tokens.json file store
+func (c *Client) tokens() TokenStore {
+	if c.tokenStore == nil {
+		c.tokenStore = NewFileTokenStore(DefaultTokenFile)
 	}
-
-	return nil
+	return c.tokenStore
 }
 
-// createEmptyTokenFile creates an empty token file
-func (c *Client) createEmptyTokenFile() error {
-	file, err := os.Create("tokens.json")
-	if err != nil {
-		return err
+// currentResetDate returns the server's current reset date, or an empty string if it cannot be fetched
+func (c *Client) currentResetDate() string {
+	var response struct {
+		Data models.ServerStatusResponse `json:"data"`
 	}
-	defer file.Close()
 
-	tokenFile := TokenFile{
-		Tokens: make(map[string]string),
+	// The status is public, and a token from before a reset would be rejected
+	ctx := context.WithValue(context.Background(), requestTokenKey{}, "")
+	if apiErr := c.executeRequest(ctx, "GET", "/", nil, nil, &response); apiErr != nil {
+		c.Logger.Debug("Failed to fetch server reset date", "error", apiErr)
+		return ""
 	}
-
-	err = json.NewEncoder(file).Encode(tokenFile)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	return response.Data.ResetDate
 }
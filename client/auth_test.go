@@ -3,12 +3,20 @@ package client
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 
 	"github.com/go-resty/resty/v2"
-	"github.com/jjkirkpatrick/spacetraders-client/internal/cache"
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	"github.com/jjkirkpatrick/spacetraders-client/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetOrRegisterToken(t *testing.T) {
@@ -23,7 +31,7 @@ func TestGetOrRegisterToken(t *testing.T) {
 		httpClient:  resty.New(),
 		context:     context.Background(),
 		retryDelay:  options.RetryDelay,
-		CacheClient: cache.NewCache(),
+		CacheClient: cache.NewMemory(cache.MemoryOptions{}),
 		Logger:      slog.Default(),
 		RateLimiter: NewRateLimiter(2.0, 10.0),
 	}
@@ -59,7 +67,7 @@ func TestTokenFileOperations(t *testing.T) {
 		httpClient:  resty.New(),
 		context:     context.Background(),
 		retryDelay:  options.RetryDelay,
-		CacheClient: cache.NewCache(),
+		CacheClient: cache.NewMemory(cache.MemoryOptions{}),
 		Logger:      slog.Default(),
 		RateLimiter: NewRateLimiter(2.0, 10.0),
 	}
@@ -156,3 +164,138 @@ func TestTokenFileOperations(t *testing.T) {
 	// Cleanup
 	_ = os.Remove("tokens.json")
 }
+
+func newAuthModeServer(t *testing.T, calls *[]string) *httptest.Server {
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*calls = append(*calls, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+		case "/register":
+			fmt.Fprint(w, `{"data":{"token":"agent-token"}}`)
+		default:
+			fmt.Fprint(w, `{"data":{}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAuthMode_Lazy(t *testing.T) {
+	var calls []string
+	server := newAuthModeServer(t, &calls)
+
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set(AccountTokenKey, TokenEntry{Token: "account-token"}))
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.AuthMode = AuthLazy
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+	assert.Empty(t, calls, "a lazy client makes no requests when created")
+
+	// Public endpoints do not trigger authentication
+	require.Nil(t, c.Get("/systems/X1-TEST", nil, nil))
+	assert.Equal(t, "", c.GetToken())
+
+	require.Nil(t, c.Get("/my/agent", nil, nil))
+	assert.Equal(t, "agent-token", c.GetToken())
+	assert.Equal(t, []string{
+		"GET /systems/X1-TEST ",
+		"POST /register Bearer account-token",
+		"GET / ",
+		"GET /my/agent Bearer agent-token",
+	}, calls)
+}
+
+func TestAuthMode_LazyWhileUnreachable(t *testing.T) {
+	options := DefaultClientOptions()
+	options.BaseURL = "http://127.0.0.1:1"
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = NewMemoryTokenStore()
+	options.AuthMode = AuthLazy
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	assert.Error(t, c.Authenticate())
+}
+
+func TestAuthMode_Anonymous(t *testing.T) {
+	var calls []string
+	server := newAuthModeServer(t, &calls)
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.Nil(t, c.Get("/factions", nil, nil))
+
+	apiErr := c.Get("/my/ships", nil, nil)
+	require.NotNil(t, apiErr)
+	assert.Equal(t, 401, apiErr.Code)
+	apiErr = c.Post("/my/ships/TEST-1/dock", nil, nil, nil)
+	require.NotNil(t, apiErr)
+
+	assert.Equal(t, []string{"GET /factions "}, calls, "authenticated requests never reach the API")
+}
+
+func TestAuthMode_Invalid(t *testing.T) {
+	options := DefaultClientOptions()
+	options.Symbol = "TEST"
+	options.AuthMode = "sometimes"
+
+	_, err := NewClient(options)
+	assert.Error(t, err)
+}
+
+func TestNewClientReleasesOnAuthFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":{"code":401,"message":"account token required"}}`)
+	}))
+	defer server.Close()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = NewMemoryTokenStore()
+	options.TelemetryOptions = &TelemetryOptions{
+		ServiceName:    "test",
+		MetricExporter: telemetry.ExporterPrometheus,
+		PrometheusAddr: addr,
+		EnableMetrics:  true,
+	}
+
+	_, err = NewClient(options)
+	require.Error(t, err)
+
+	// The Prometheus listener was closed along with the rest of the telemetry
+	listener, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	require.NoError(t, listener.Close())
+}
@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+)
+
+// CheckpointPage is one completed page of a paginated crawl
+type CheckpointPage struct {
+	Page int         `json:"page"`
+	Meta models.Meta `json:"meta"`
+	// Items is the page's items encoded as a JSON array
+	Items json.RawMessage `json:"items"`
+}
+
+// CheckpointStore records the pages of paginated crawls as they complete, so an
+// interrupted crawl resumes where it stopped. Crawls are identified by key.
+type CheckpointStore interface {
+	// Load returns the pages saved for key in page order, or none if the crawl has not started
+	Load(key string) ([]CheckpointPage, error)
+	// Save records a completed page
+	Save(key string, page CheckpointPage) error
+	// Delete discards the crawl, e.g. once it has completed
+	Delete(key string) error
+}
+
+// FileCheckpointStore keeps each crawl in a JSON lines file in a directory, appending a
+// line per completed page. A line left incomplete by a crash is ignored when loading.
+type FileCheckpointStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFileCheckpointStore creates a store keeping crawls in dir, which is created if needed
+func NewFileCheckpointStore(dir string) *FileCheckpointStore {
+	return &FileCheckpointStore{dir: dir}
+}
+
+var unsafeCheckpointChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// path returns the file holding the crawl for key
+func (s *FileCheckpointStore) path(key string) string {
+	return filepath.Join(s.dir, unsafeCheckpointChars.ReplaceAllString(key, "_")+".jsonl")
+}
+
+// Load implements CheckpointStore
+func (s *FileCheckpointStore) Load(key string) ([]CheckpointPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.Open(s.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	pages := make(map[int]CheckpointPage)
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// A final line without a newline was cut short while being written
+			break
+		}
+
+		var page CheckpointPage
+		if err := json.Unmarshal(bytes.TrimSpace(line), &page); err != nil {
+			return nil, fmt.Errorf("failed to decode checkpoint page: %w", err)
+		}
+		pages[page.Page] = page
+	}
+
+	return sortedCheckpointPages(pages), nil
+}
+
+// Save implements CheckpointStore
+func (s *FileCheckpointStore) Save(key string, page CheckpointPage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line, err := json.Marshal(page)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint page: %w", err)
+	}
+
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	file, err := os.OpenFile(s.path(key), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint page: %w", err)
+	}
+	return file.Sync()
+}
+
+// Delete implements CheckpointStore
+func (s *FileCheckpointStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// MemoryCheckpointStore keeps crawls in memory, resuming crawls interrupted by errors
+// within the same process
+type MemoryCheckpointStore struct {
+	mu     sync.Mutex
+	crawls map[string]map[int]CheckpointPage
+}
+
+// NewMemoryCheckpointStore creates an empty in-memory checkpoint store
+func NewMemoryCheckpointStore() *MemoryCheckpointStore {
+	return &MemoryCheckpointStore{crawls: make(map[string]map[int]CheckpointPage)}
+}
+
+// Load implements CheckpointStore
+func (s *MemoryCheckpointStore) Load(key string) ([]CheckpointPage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return sortedCheckpointPages(s.crawls[key]), nil
+}
+
+// Save implements CheckpointStore
+func (s *MemoryCheckpointStore) Save(key string, page CheckpointPage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.crawls[key] == nil {
+		s.crawls[key] = make(map[int]CheckpointPage)
+	}
+	s.crawls[key][page.Page] = page
+	return nil
+}
+
+// Delete implements CheckpointStore
+func (s *MemoryCheckpointStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.crawls, key)
+	return nil
+}
+
+func sortedCheckpointPages(pages map[int]CheckpointPage) []CheckpointPage {
+	sorted := make([]CheckpointPage, 0, len(pages))
+	for _, page := range pages {
+		sorted = append(sorted, page)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Page < sorted[j].Page })
+	return sorted
+}
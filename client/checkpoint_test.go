@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCheckpointStore(t *testing.T, store CheckpointStore) {
+	pages, err := store.Load("systems")
+	require.NoError(t, err)
+	assert.Empty(t, pages)
+
+	meta := models.Meta{Limit: 10, Total: 30}
+	require.NoError(t, store.Save("systems", CheckpointPage{Page: 2, Meta: meta, Items: json.RawMessage(`[11,12]`)}))
+	require.NoError(t, store.Save("systems", CheckpointPage{Page: 1, Meta: meta, Items: json.RawMessage(`[1,2]`)}))
+	require.NoError(t, store.Save("other", CheckpointPage{Page: 1, Meta: meta, Items: json.RawMessage(`[]`)}))
+
+	pages, err = store.Load("systems")
+	require.NoError(t, err)
+	require.Len(t, pages, 2)
+	assert.Equal(t, 1, pages[0].Page)
+	assert.Equal(t, 2, pages[1].Page)
+	assert.JSONEq(t, `[11,12]`, string(pages[1].Items))
+
+	require.NoError(t, store.Delete("systems"))
+	pages, err = store.Load("systems")
+	require.NoError(t, err)
+	assert.Empty(t, pages)
+
+	pages, err = store.Load("other")
+	require.NoError(t, err)
+	assert.Len(t, pages, 1)
+}
+
+func TestMemoryCheckpointStore(t *testing.T) {
+	testCheckpointStore(t, NewMemoryCheckpointStore())
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "checkpoints")
+	testCheckpointStore(t, NewFileCheckpointStore(dir))
+
+	// A page cut short by a crash is ignored
+	store := NewFileCheckpointStore(dir)
+	require.NoError(t, store.Save("crash", CheckpointPage{Page: 1, Items: json.RawMessage(`[1]`)}))
+
+	// Checkpoints are private to the user running the client
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	info, err = os.Stat(store.path("crash"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	file, err := os.OpenFile(store.path("crash"), os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"page":2,"items":[`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	pages, err := store.Load("crash")
+	require.NoError(t, err)
+	require.Len(t, pages, 1)
+	assert.Equal(t, 1, pages[0].Page)
+}
+
+func TestPaginatorResumesFromCheckpoint(t *testing.T) {
+	store := NewMemoryCheckpointStore()
+
+	var fetched, failures atomic.Int32
+	notFound := &models.APIError{Code: 404, Message: "not found"}
+	failing := func(meta models.Meta) ([]int, models.Meta, error) {
+		if meta.Page == 3 {
+			failures.Add(1)
+			return nil, meta, notFound.AsError()
+		}
+		return testPages(45, &fetched, nil)(meta)
+	}
+
+	items, err := newTestPaginator(failing).WithCheckpoint(store, "numbers").FetchAllPages()
+	require.Error(t, err)
+	assert.ErrorAs(t, err, &notFound)
+	assert.Len(t, items, 20)
+	assert.Equal(t, int32(1), failures.Load(), "permanent errors are not retried")
+
+	saved, err := store.Load("numbers")
+	require.NoError(t, err)
+	assert.Len(t, saved, 2)
+
+	// The resumed crawl fetches only the pages that were not saved
+	fetched.Store(0)
+	items, err = newTestPaginator(testPages(45, &fetched, nil)).WithCheckpoint(store, "numbers").FetchAllPages()
+	require.NoError(t, err)
+	require.Len(t, items, 45)
+	for i, item := range items {
+		assert.Equal(t, i+1, item)
+	}
+	assert.Equal(t, int32(3), fetched.Load())
+
+	saved, err = store.Load("numbers")
+	require.NoError(t, err)
+	assert.Empty(t, saved, "completed crawls are deleted")
+}
+
+func TestPaginatorRetriesTransientErrors(t *testing.T) {
+	var fetched, failures atomic.Int32
+	pages := testPages(25, &fetched, nil)
+	flaky := func(meta models.Meta) ([]int, models.Meta, error) {
+		if meta.Page == 2 && failures.Add(1) <= 2 {
+			return nil, meta, models.APIError{Code: 502, Message: "bad gateway"}.AsError()
+		}
+		return pages(meta)
+	}
+
+	p := NewPaginator(flaky).WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond})
+	p.Meta.Limit = 10
+
+	items, err := p.FetchAllPages()
+	require.NoError(t, err)
+	assert.Len(t, items, 25)
+	assert.Equal(t, int32(3), failures.Load())
+
+	// Retries stop at MaxRetries
+	failures.Store(0)
+	p = NewPaginator(flaky).WithRetryPolicy(RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond})
+	p.Meta.Limit = 10
+
+	_, err = p.FetchAllPages()
+	assert.ErrorContains(t, err, "page 2 after 2 attempts")
+}
+
+func TestPaginatorDoesNotRetryRateLimits(t *testing.T) {
+	var attempts atomic.Int32
+	limited := func(meta models.Meta) ([]int, models.Meta, error) {
+		attempts.Add(1)
+		return nil, meta, models.APIError{Code: 429, Message: "rate limited"}.AsError()
+	}
+
+	p := NewPaginator(limited).WithRetryPolicy(RetryPolicy{MaxRetries: 3, InitialBackoff: time.Millisecond})
+	p.Meta.Limit = 10
+
+	_, err := p.FetchAllPages()
+	require.Error(t, err)
+	assert.Equal(t, int32(1), attempts.Load(), "the request queue already retried the rate limit")
+}
+
+func TestPaginatorCheckpointContextCancelled(t *testing.T) {
+	store := NewMemoryCheckpointStore()
+
+	var fetched atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	count := 0
+	for _, err := range newTestPaginator(testPages(100, &fetched, nil)).WithCheckpoint(store, "numbers").All(ctx) {
+		if err != nil {
+			break
+		}
+		count++
+		if count == 25 {
+			cancel()
+		}
+	}
+
+	saved, err := store.Load("numbers")
+	require.NoError(t, err)
+	assert.Len(t, saved, 3, "pages yielded before cancelling are kept")
+}
@@ -5,19 +5,24 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-resty/resty/v2"
-	"github.com/jjkirkpatrick/spacetraders-client/internal/cache"
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	internalcache "github.com/jjkirkpatrick/spacetraders-client/internal/cache"
 	"github.com/jjkirkpatrick/spacetraders-client/internal/telemetry"
 	"github.com/jjkirkpatrick/spacetraders-client/models"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
@@ -31,8 +36,22 @@ type TelemetryOptions struct {
 	ServiceVersion string
 	// Environment is the deployment environment (e.g., "development", "production")
 	Environment string
-	// OTLPEndpoint is the endpoint for your OpenTelemetry collector (required if telemetry is enabled)
+	// OTLPEndpoint is the endpoint for your OpenTelemetry collector
+	// (required if any enabled signal is exported over OTLP)
 	OTLPEndpoint string
+	// OTLPHTTPEndpoint is the endpoint for signals exported with telemetry.ExporterOTLPHTTP,
+	// as host:port or a full URL (defaults to OTLPEndpoint)
+	OTLPHTTPEndpoint string
+	// MetricExporter selects how metrics are exported (default: telemetry.ExporterOTLP).
+	// telemetry.ExporterPrometheus exposes them for scraping instead, see Client.MetricsHandler.
+	MetricExporter telemetry.Exporter
+	// TraceExporter selects how spans are exported (default: telemetry.ExporterOTLP)
+	TraceExporter telemetry.Exporter
+	// LogExporter selects how log records are exported (default: telemetry.ExporterOTLP)
+	LogExporter telemetry.Exporter
+	// PrometheusAddr is the address the client serves /metrics on when using the
+	// Prometheus exporter, e.g. ":9464". Leave empty to mount Client.MetricsHandler yourself.
+	PrometheusAddr string
 	// MetricInterval is how often metrics are exported (defaults to 5s)
 	MetricInterval time.Duration
 	// TraceSampleRate controls the fraction of traces to sample (0.0 to 1.0)
@@ -64,36 +83,150 @@ type ClientOptions struct {
 	TelemetryOptions *TelemetryOptions
 	// Request queue size (default: 100)
 	RequestQueueSize int
+	// DryRun simulates mutating requests from previously fetched state instead of sending them.
+	// GET requests are still sent to the API.
+	DryRun bool
+	// Journal receives an entry for every successful mutating request (optional).
+	// The client closes it when the client is closed.
+	Journal JournalSink
+	// RedactionPatterns are extra regular expressions for secrets to remove from logs,
+	// journal entries and spans, in addition to DefaultRedactionPatterns
+	RedactionPatterns []string
+	// TokenStore holds agent and account tokens (optional). Defaults to tokens.json
+	// in the working directory.
+	TokenStore TokenStore
+	// AutoRecoverReset re-registers the agent with the account token when a game reset is
+	// detected, then retries the failed request once. Callbacks registered with OnReset
+	// run after each recovery.
+	AutoRecoverReset bool
+	// AuthMode controls when the client authenticates (default: AuthEager).
+	// Symbol and Faction are not needed for AuthAnonymous.
+	AuthMode AuthMode
+	// RateLimitStrategy controls how requests are paced (default: RateLimitAdaptive)
+	RateLimitStrategy RateLimitStrategy
+	// RetryPolicy controls how rate-limited requests are retried (default: DefaultRetryPolicy())
+	RetryPolicy *RetryPolicy
+	// Transport tunes timeouts, proxying, TLS, connection pooling and the user agent
+	// (default: DefaultTransportOptions()). Unset fields use their defaults.
+	Transport *TransportOptions
+	// CacheMaxEntries bounds the number of items in CacheClient, evicting the least
+	// recently used first (default: DefaultCacheMaxEntries)
+	CacheMaxEntries int
+	// CacheDir keeps static universe data on disk, so it survives restarts (optional). Only
+	// responses cached until the reset, such as systems, waypoints and jump gates, and
+	// pathfinding graphs are written there, through LoadStatic and SaveStatic; the rest of
+	// CacheClient stays in memory. Data from before the current reset is deleted.
+	CacheDir string
+	// CachePolicy sets which GET responses are cached and for how long
+	// (default: DefaultCachePolicy()). An empty policy disables response caching.
+	CachePolicy *CachePolicy
+	// Cache replaces the default in-memory CacheClient (optional). CacheMaxEntries is
+	// ignored, and the client neither reports its metrics nor closes it.
+	Cache cache.Cache
 }
 
+const (
+	// DefaultCacheMaxEntries is the default bound on the number of cached items
+	DefaultCacheMaxEntries = 10000
+	// cacheJanitorInterval is how often expired items are removed from CacheClient
+	cacheJanitorInterval = time.Minute
+	// telemetryShutdownTimeout bounds flushing telemetry when a client fails to initialize
+	telemetryShutdownTimeout = 5 * time.Second
+	// staticRetryInterval is how long the static data store waits to fetch the reset date
+	// again after failing to
+	staticRetryInterval = time.Minute
+)
+
+// RateLimitStrategy controls how the client paces requests
+type RateLimitStrategy string
+
+const (
+	// RateLimitAdaptive starts at RequestsPerSecond and follows the limits reported by the API
+	RateLimitAdaptive RateLimitStrategy = "adaptive"
+	// RateLimitFixed sends at most RequestsPerSecond and ignores the limits reported by the API
+	RateLimitFixed RateLimitStrategy = "fixed"
+)
+
 // Client represents the SpaceTraders API client
 type Client struct {
-	context     context.Context
-	baseURL     string
-	token       string
-	httpClient  *resty.Client
-	retryDelay  time.Duration
-	AgentSymbol string
-	CacheClient *cache.Cache
-	Logger      *slog.Logger
-	RateLimiter *RateLimiter
+	context    context.Context
+	baseURL    string
+	token      string
+	tokenMu    sync.RWMutex // Guards token, which recovery replaces while requests run
+	httpClient *resty.Client
+	retryDelay time.Duration
+	// Attempts taking at least slowThreshold are reported as slow, unless it is negative
+	slowThreshold time.Duration
+	AgentSymbol   string
+	CacheClient   cache.Cache
+	Logger        *slog.Logger
+	RateLimiter   *RateLimiter
 	// Request queue
 	requestQueue *RequestQueue
 
+	// Static universe data kept on disk across restarts, nil without ClientOptions.CacheDir
+	staticCache *internalcache.DiskStore
+	staticMu    sync.Mutex
+	// When the reset date last failed to resolve, to back off before fetching it again
+	staticFailedAt time.Time
+	// The default CacheClient, nil when ClientOptions.Cache is set
+	memoryCache *cache.Memory
+	// Which GET responses are cached in CacheClient
+	cachePolicy CachePolicy
+	// Rules removing cached data made stale by mutating requests
+	invalidations  []invalidationRule
+	invalidationMu sync.RWMutex
+
 	// Game reset notification channel
 	// This channel will receive a message when a token version mismatch is detected
 	// indicating that the game has been reset
 	GameResetCh chan struct{}
 
+	// Dry-run state, nil unless dry-run mode is enabled
+	dryRun *dryRunState
+
+	// Action journal, nil if not configured
+	journal JournalSink
+
+	// Removes tokens and other secrets from logs, journal entries and spans
+	redactor *Redactor
+
+	// Token store, nil until first used if not configured
+	tokenStore TokenStore
+	// Account token used to register the agent, overriding the one in the token store
+	accountToken string
+
+	// Authentication mode, and whether a lazy client has authenticated yet
+	authMode      AuthMode
+	authMu        sync.Mutex
+	authenticated bool
+
+	// Set when the client belongs to an AgentManager, which closes the shared telemetry and journal
+	managed bool
+
+	// Registration details and callbacks used to recover from game resets
+	faction          string
+	email            string
+	autoRecoverReset bool
+	resetMu          sync.Mutex
+	recovering       atomic.Bool
+	resetCallbacks   []func(ResetEvent)
+
 	// Telemetry (metrics only)
-	telemetryProviders *telemetry.Providers
-	meter              metric.Meter
+	telemetryProviders  *telemetry.Providers
+	meter               metric.Meter
+	metricsRegistration metric.Registration
 
 	// API request metrics
 	requestCounter  metric.Int64Counter
 	requestDuration metric.Float64Histogram
 	errorCounter    metric.Int64Counter
 	retryCounter    metric.Int64Counter
+	slowCounter     metric.Int64Counter
+
+	// Response cache metrics
+	cacheHitCounter  metric.Int64Counter
+	cacheMissCounter metric.Int64Counter
 
 	// Rate limit metrics
 	rateLimitGauge    metric.Float64ObservableGauge
@@ -123,6 +256,8 @@ type RateLimiter struct {
 	resetTime time.Time
 	// Add a channel to coordinate waiting for reset
 	resetChan chan struct{}
+	// fixed limiters ignore the limits reported by the API
+	fixed bool
 }
 
 func NewRateLimiter(staticRate, burstRate float64) *RateLimiter {
@@ -137,6 +272,18 @@ func NewRateLimiter(staticRate, burstRate float64) *RateLimiter {
 	}
 }
 
+// newRateLimiter creates the rate limiter for a strategy, sending requestsPerSecond
+// (default: 2) until the API reports otherwise
+func newRateLimiter(strategy RateLimitStrategy, requestsPerSecond float32) *RateLimiter {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = 2
+	}
+
+	rl := NewRateLimiter(float64(requestsPerSecond), 30)
+	rl.fixed = strategy == RateLimitFixed
+	return rl
+}
+
 func (rl *RateLimiter) Wait(ctx context.Context) error {
 	rl.mu.Lock()
 	// If we have no remaining requests, we need to wait for reset
@@ -199,6 +346,10 @@ func (rl *RateLimiter) updateLimits(limitPerSecond float64, limitBurst int, rema
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
+	if rl.fixed {
+		return
+	}
+
 	if limitPerSecond > 0 && limitPerSecond != rl.limitPerSecond {
 		rl.limitPerSecond = limitPerSecond
 		// Allow bursting up to 2 requests, which matches the API's per-second rate
@@ -240,6 +391,7 @@ func DefaultClientOptions() ClientOptions {
 		TelemetryOptions: nil,
 		// Default request queue size
 		RequestQueueSize: 100,
+		CacheMaxEntries:  DefaultCacheMaxEntries,
 	}
 }
 
@@ -248,6 +400,9 @@ func DefaultTelemetryOptions() *TelemetryOptions {
 	return &TelemetryOptions{
 		Environment:     "development",
 		MetricInterval:  5 * time.Second,
+		MetricExporter:  telemetry.ExporterOTLP,
+		TraceExporter:   telemetry.ExporterOTLP,
+		LogExporter:     telemetry.ExporterOTLP,
 		TraceSampleRate: 1.0, // Sample all traces by default
 		EnableMetrics:   true,
 		EnableTracing:   true,
@@ -260,251 +415,439 @@ func DefaultTelemetryOptions() *TelemetryOptions {
 
 // NewClient creates a new instance of the SpaceTraders API client
 func NewClient(options ClientOptions) (*Client, error) {
-	if options.Symbol == "" {
+	return newClient(options, nil)
+}
+
+// sharedResources are created once by an AgentManager and shared by all of its clients
+type sharedResources struct {
+	telemetryProviders *telemetry.Providers
+	// rateLimiter is nil when each agent has its own limiter
+	rateLimiter  *RateLimiter
+	tokenStore   TokenStore
+	accountToken string
+}
+
+// newClient creates a client, using shared resources instead of creating its own if shared is not nil
+func newClient(options ClientOptions, shared *sharedResources) (*Client, error) {
+	switch options.AuthMode {
+	case "":
+		options.AuthMode = AuthEager
+	case AuthEager, AuthLazy, AuthAnonymous:
+	default:
+		return nil, fmt.Errorf("invalid auth mode: %s", options.AuthMode)
+	}
+
+	switch options.RateLimitStrategy {
+	case "":
+		options.RateLimitStrategy = RateLimitAdaptive
+	case RateLimitAdaptive, RateLimitFixed:
+	default:
+		return nil, fmt.Errorf("invalid rate limit strategy: %s", options.RateLimitStrategy)
+	}
+
+	if options.Symbol == "" && options.AuthMode != AuthAnonymous {
 		return nil, fmt.Errorf("symbol is required")
 	}
 
+	redactor, err := NewRedactor(options.RedactionPatterns...)
+	if err != nil {
+		return nil, err
+	}
+
 	// Configure slog logger using custom handler if provided
-	var logger *slog.Logger
+	var handler slog.Handler
 	if options.Handler != nil {
-		logger = slog.New(options.Handler)
+		handler = options.Handler
 	} else {
-		defaultHandler := slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
+		handler = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
 			Level: options.LogLevel,
 			ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
 				return a
 			},
 		})
-		logger = slog.New(defaultHandler)
+	}
+	// Every record is redacted before it reaches the handler, including the OTLP log exporter
+	logger := slog.New(NewRedactingHandler(handler, redactor)).With("agent", options.Symbol)
+
+	transport := options.Transport.withDefaults()
+	httpClient, err := newHTTPClient(transport)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheClient := options.Cache
+	var memoryCache *cache.Memory
+	if cacheClient == nil {
+		cacheMaxEntries := options.CacheMaxEntries
+		if cacheMaxEntries <= 0 {
+			cacheMaxEntries = DefaultCacheMaxEntries
+		}
+		memoryCache = cache.NewMemory(cache.MemoryOptions{
+			MaxEntries:      cacheMaxEntries,
+			JanitorInterval: cacheJanitorInterval,
+		})
+		cacheClient = memoryCache
+	}
+	var staticCache *internalcache.DiskStore
+	if options.CacheDir != "" {
+		staticCache = internalcache.NewDiskStore(options.CacheDir)
+	}
+	cachePolicy := DefaultCachePolicy()
+	if options.CachePolicy != nil {
+		cachePolicy = *options.CachePolicy
 	}
 
 	// Create initial client with basic logging
 	client := &Client{
-		baseURL:     options.BaseURL,
-		httpClient:  resty.New(),
-		context:     context.Background(),
-		retryDelay:  options.RetryDelay,
-		AgentSymbol: options.Symbol,
-		CacheClient: cache.NewCache(),
-		Logger:      logger,
-		RateLimiter: NewRateLimiter(2, 30),
+		baseURL:          options.BaseURL,
+		httpClient:       httpClient,
+		slowThreshold:    transport.SlowRequestThreshold,
+		context:          context.Background(),
+		retryDelay:       options.RetryDelay,
+		AgentSymbol:      options.Symbol,
+		CacheClient:      cacheClient,
+		staticCache:      staticCache,
+		memoryCache:      memoryCache,
+		cachePolicy:      cachePolicy,
+		invalidations:    defaultInvalidations(),
+		Logger:           logger,
+		RateLimiter:      newRateLimiter(options.RateLimitStrategy, options.RequestsPerSecond),
+		journal:          options.Journal,
+		redactor:         redactor,
+		tokenStore:       options.TokenStore,
+		faction:          options.Faction,
+		email:            options.Email,
+		autoRecoverReset: options.AutoRecoverReset,
+		authMode:         options.AuthMode,
 		// Initialize the game reset notification channel with a buffer
 		// to ensure sending to this channel never blocks
 		GameResetCh: make(chan struct{}, 1),
 	}
 
-	// Initialize telemetry if configured
-	if options.TelemetryOptions != nil {
-		// Convert public options to internal config
-		telemetryConfig := telemetry.Config{
-			ServiceName:     options.TelemetryOptions.ServiceName,
-			ServiceVersion:  options.TelemetryOptions.ServiceVersion,
-			Environment:     options.TelemetryOptions.Environment,
-			OTLPEndpoint:    options.TelemetryOptions.OTLPEndpoint,
-			MetricInterval:  options.TelemetryOptions.MetricInterval,
-			TraceSampleRate: options.TelemetryOptions.TraceSampleRate,
-			EnableMetrics:   options.TelemetryOptions.EnableMetrics,
-			EnableTracing:   options.TelemetryOptions.EnableTracing,
-			EnableLogging:   options.TelemetryOptions.EnableLogging,
-		}
-
-		// Convert additional attributes to KeyValue pairs
-		if options.TelemetryOptions.AdditionalAttributes != nil {
-			attrs := make([]attribute.KeyValue, 0, len(options.TelemetryOptions.AdditionalAttributes))
-			for k, v := range options.TelemetryOptions.AdditionalAttributes {
-				attrs = append(attrs, attribute.String(k, v))
-			}
-			telemetryConfig.AdditionalAttrs = attrs
+	// Stop the cache janitor and telemetry if the client cannot be created
+	initialized := false
+	defer func() {
+		if !initialized {
+			client.release()
 		}
+	}()
 
-		// Add gRPC dial options if provided
-		if options.TelemetryOptions.GRPCDialOptions != nil {
-			telemetryConfig.GRPCDialOptions = options.TelemetryOptions.GRPCDialOptions
-		}
+	if options.DryRun {
+		client.dryRun = newDryRunState()
+		client.Logger.Warn("Dry-run mode enabled: mutating requests will be simulated and not sent to the API")
+	}
 
-		providers, terr := telemetry.InitTelemetry(client.context, telemetryConfig)
+	if shared != nil {
+		client.managed = true
+		client.telemetryProviders = shared.telemetryProviders
+		client.tokenStore = shared.tokenStore
+		client.accountToken = shared.accountToken
+		if shared.rateLimiter != nil {
+			client.RateLimiter = shared.rateLimiter
+		}
+	} else if options.TelemetryOptions != nil {
+		// Initialize telemetry if configured
+		providers, terr := newTelemetryProviders(client.context, options.TelemetryOptions)
 		if terr != nil {
-			return nil, fmt.Errorf("failed to initialize telemetry: %w", terr)
+			return nil, terr
 		}
 		client.telemetryProviders = providers
+	}
 
-		// Initialize metrics (if enabled)
-		client.meter = otel.GetMeterProvider().Meter("spacetraders-client")
-
-		var merr error
-
-		// API request metrics
-		client.requestCounter, merr = client.meter.Int64Counter("api_requests_total",
-			metric.WithDescription("Total number of API requests made"),
-			metric.WithUnit("{requests}"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create request counter: %w", merr)
+	if options.TelemetryOptions != nil {
+		if merr := client.initMetrics(); merr != nil {
+			return nil, merr
 		}
+	}
 
-		client.requestDuration, merr = client.meter.Float64Histogram("api_request_duration_seconds",
-			metric.WithDescription("Duration of API requests in seconds"),
-			metric.WithUnit("s"),
-			metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create request duration histogram: %w", merr)
+	// Lazy clients authenticate on their first authenticated request, anonymous clients never do
+	if options.AuthMode == AuthEager {
+		if apiError := client.getOrRegisterToken(options.Faction, options.Symbol, options.Email); apiError != nil {
+			return nil, apiError
 		}
+		client.authenticated = true
+	}
 
-		client.errorCounter, merr = client.meter.Int64Counter("api_errors_total",
-			metric.WithDescription("Total number of API errors"),
-			metric.WithUnit("{errors}"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create error counter: %w", merr)
-		}
+	// Initialize the request queue
+	queueSize := options.RequestQueueSize
+	if queueSize <= 0 {
+		queueSize = 100 // Default size
+	}
+	retryPolicy := DefaultRetryPolicy()
+	if options.RetryPolicy != nil {
+		retryPolicy = *options.RetryPolicy
+	}
+	client.requestQueue = newRequestQueue(client.context, client, queueSize, retryPolicy, transport.Timeout)
 
-		client.retryCounter, merr = client.meter.Int64Counter("api_retries_total",
-			metric.WithDescription("Total number of API request retries"),
-			metric.WithUnit("{retries}"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create retry counter: %w", merr)
-		}
+	client.Logger.Info("New SpaceTraders client initialized",
+		"baseURL", client.baseURL,
+		"rateLimit", options.RequestsPerSecond,
+		"rateLimitStrategy", options.RateLimitStrategy,
+		"queueSize", queueSize,
+		"authMode", options.AuthMode)
+	initialized = true
+	return client, nil
+}
 
-		// Rate limit metrics
-		client.rateLimitGauge, merr = client.meter.Float64ObservableGauge("api_rate_limit",
-			metric.WithDescription("Current API rate limit settings"),
-			metric.WithUnit("{requests_per_second}"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create rate limit gauge: %w", merr)
+// release stops what newClient started for a client that failed to initialize: the
+// cache janitor, the metric callbacks and telemetry that is not shared with other clients
+func (c *Client) release() {
+	if c.metricsRegistration != nil {
+		if err := c.metricsRegistration.Unregister(); err != nil {
+			c.Logger.Warn("Failed to unregister metric callbacks", "error", err)
 		}
-
-		client.remainingRequests, merr = client.meter.Int64ObservableGauge("api_remaining_requests",
-			metric.WithDescription("Number of API requests remaining before rate limit"),
-			metric.WithUnit("{requests}"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create remaining requests gauge: %w", merr)
+	}
+	if c.memoryCache != nil {
+		if err := c.memoryCache.Close(); err != nil {
+			c.Logger.Warn("Failed to close cache", "error", err)
 		}
-
-		client.resetTimeGauge, merr = client.meter.Float64ObservableGauge("api_rate_limit_reset",
-			metric.WithDescription("Time until rate limit reset in seconds"),
-			metric.WithUnit("s"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create reset time gauge: %w", merr)
+	}
+	if !c.managed && c.telemetryProviders != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), telemetryShutdownTimeout)
+		defer cancel()
+		if err := c.telemetryProviders.Shutdown(ctx); err != nil {
+			c.Logger.Warn("Failed to shut down telemetry", "error", err)
 		}
+	}
+}
 
-		// Queue metrics
-		client.queueLengthGauge, merr = client.meter.Int64ObservableGauge("api_queue_length",
-			metric.WithDescription("Number of requests in the queue"),
-			metric.WithUnit("{requests}"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create queue length gauge: %w", merr)
-		}
+// newTelemetryProviders initializes the OpenTelemetry providers described by options
+func newTelemetryProviders(ctx context.Context, options *TelemetryOptions) (*telemetry.Providers, error) {
+	// Convert public options to internal config
+	telemetryConfig := telemetry.Config{
+		ServiceName:      options.ServiceName,
+		ServiceVersion:   options.ServiceVersion,
+		Environment:      options.Environment,
+		OTLPEndpoint:     options.OTLPEndpoint,
+		OTLPHTTPEndpoint: options.OTLPHTTPEndpoint,
+		MetricExporter:   options.MetricExporter,
+		TraceExporter:    options.TraceExporter,
+		LogExporter:      options.LogExporter,
+		PrometheusAddr:   options.PrometheusAddr,
+		MetricInterval:   options.MetricInterval,
+		TraceSampleRate:  options.TraceSampleRate,
+		EnableMetrics:    options.EnableMetrics,
+		EnableTracing:    options.EnableTracing,
+		EnableLogging:    options.EnableLogging,
+	}
 
-		client.queueWaitTime, merr = client.meter.Float64Histogram("api_queue_wait_time_seconds",
-			metric.WithDescription("Time requests spend waiting in the queue"),
-			metric.WithUnit("s"),
-			metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create queue wait time histogram: %w", merr)
+	// Convert additional attributes to KeyValue pairs
+	if options.AdditionalAttributes != nil {
+		attrs := make([]attribute.KeyValue, 0, len(options.AdditionalAttributes))
+		for k, v := range options.AdditionalAttributes {
+			attrs = append(attrs, attribute.String(k, v))
 		}
+		telemetryConfig.AdditionalAttrs = attrs
+	}
 
-		client.queueProcessTime, merr = client.meter.Float64Histogram("api_queue_process_time_seconds",
-			metric.WithDescription("Time taken to process requests from the queue"),
-			metric.WithUnit("s"),
-			metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create queue process time histogram: %w", merr)
-		}
+	// Add gRPC dial options if provided
+	if options.GRPCDialOptions != nil {
+		telemetryConfig.GRPCDialOptions = options.GRPCDialOptions
+	}
 
-		client.avgQueueTimeGauge, merr = client.meter.Float64ObservableGauge("api_avg_queue_time_seconds",
-			metric.WithDescription("Average time requests spend in the queue"),
-			metric.WithUnit("s"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create average queue time gauge: %w", merr)
-		}
+	providers, err := telemetry.InitTelemetry(ctx, telemetryConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize telemetry: %w", err)
+	}
+	return providers, nil
+}
 
-		client.avgProcessTimeGauge, merr = client.meter.Float64ObservableGauge("api_avg_process_time_seconds",
-			metric.WithDescription("Average time to process requests from the queue"),
-			metric.WithUnit("s"),
-		)
-		if merr != nil {
-			return nil, fmt.Errorf("failed to create average process time gauge: %w", merr)
+// initMetrics creates the client's instruments on the global meter provider. Every observation
+// is labelled with the agent, so several clients can report to the same provider.
+func (c *Client) initMetrics() error {
+	// Initialize metrics (if enabled)
+	c.meter = otel.GetMeterProvider().Meter("spacetraders-client")
+
+	var merr error
+
+	// API request metrics
+	c.requestCounter, merr = c.meter.Int64Counter("api_requests_total",
+		metric.WithDescription("Total number of API requests made"),
+		metric.WithUnit("{requests}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create request counter: %w", merr)
+	}
+
+	c.requestDuration, merr = c.meter.Float64Histogram("api_request_duration_seconds",
+		metric.WithDescription("Duration of API requests in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create request duration histogram: %w", merr)
+	}
+
+	c.errorCounter, merr = c.meter.Int64Counter("api_errors_total",
+		metric.WithDescription("Total number of API errors"),
+		metric.WithUnit("{errors}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create error counter: %w", merr)
+	}
+
+	c.slowCounter, merr = c.meter.Int64Counter("api_slow_requests_total",
+		metric.WithDescription("Total number of API requests slower than the slow request threshold"),
+		metric.WithUnit("{requests}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create slow request counter: %w", merr)
+	}
+
+	c.cacheHitCounter, merr = c.meter.Int64Counter("api_cache_hits_total",
+		metric.WithDescription("Total number of GET requests answered from the response cache"),
+		metric.WithUnit("{requests}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create cache hit counter: %w", merr)
+	}
+
+	c.cacheMissCounter, merr = c.meter.Int64Counter("api_cache_misses_total",
+		metric.WithDescription("Total number of cacheable GET requests sent to the API"),
+		metric.WithUnit("{requests}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create cache miss counter: %w", merr)
+	}
+
+	c.retryCounter, merr = c.meter.Int64Counter("api_retries_total",
+		metric.WithDescription("Total number of API request retries"),
+		metric.WithUnit("{retries}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create retry counter: %w", merr)
+	}
+
+	// Rate limit metrics
+	c.rateLimitGauge, merr = c.meter.Float64ObservableGauge("api_rate_limit",
+		metric.WithDescription("Current API rate limit settings"),
+		metric.WithUnit("{requests_per_second}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create rate limit gauge: %w", merr)
+	}
+
+	c.remainingRequests, merr = c.meter.Int64ObservableGauge("api_remaining_requests",
+		metric.WithDescription("Number of API requests remaining before rate limit"),
+		metric.WithUnit("{requests}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create remaining requests gauge: %w", merr)
+	}
+
+	c.resetTimeGauge, merr = c.meter.Float64ObservableGauge("api_rate_limit_reset",
+		metric.WithDescription("Time until rate limit reset in seconds"),
+		metric.WithUnit("s"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create reset time gauge: %w", merr)
+	}
+
+	// Queue metrics
+	c.queueLengthGauge, merr = c.meter.Int64ObservableGauge("api_queue_length",
+		metric.WithDescription("Number of requests in the queue"),
+		metric.WithUnit("{requests}"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create queue length gauge: %w", merr)
+	}
+
+	c.queueWaitTime, merr = c.meter.Float64Histogram("api_queue_wait_time_seconds",
+		metric.WithDescription("Time requests spend waiting in the queue"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create queue wait time histogram: %w", merr)
+	}
+
+	c.queueProcessTime, merr = c.meter.Float64Histogram("api_queue_process_time_seconds",
+		metric.WithDescription("Time taken to process requests from the queue"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create queue process time histogram: %w", merr)
+	}
+
+	c.avgQueueTimeGauge, merr = c.meter.Float64ObservableGauge("api_avg_queue_time_seconds",
+		metric.WithDescription("Average time requests spend in the queue"),
+		metric.WithUnit("s"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create average queue time gauge: %w", merr)
+	}
+
+	c.avgProcessTimeGauge, merr = c.meter.Float64ObservableGauge("api_avg_process_time_seconds",
+		metric.WithDescription("Average time to process requests from the queue"),
+		metric.WithUnit("s"),
+	)
+	if merr != nil {
+		return fmt.Errorf("failed to create average process time gauge: %w", merr)
+	}
+
+	if c.memoryCache != nil {
+		if err := c.memoryCache.RegisterMetrics(c.meter, attribute.String("agent", c.AgentSymbol)); err != nil {
+			return fmt.Errorf("failed to register cache metrics: %w", err)
 		}
+	}
 
-		// Register callback for observable metrics
-		_, err := client.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
-			// Rate limit metrics
-			o.ObserveFloat64(client.rateLimitGauge, client.RateLimiter.limitPerSecond,
+	// Register callback for observable metrics
+	registration, err := c.meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		// Rate limit metrics
+		o.ObserveFloat64(c.rateLimitGauge, c.RateLimiter.limitPerSecond,
+			metric.WithAttributes(
+				attribute.String("type", "static"),
+				attribute.String("agent", c.AgentSymbol),
+			))
+		o.ObserveInt64(c.remainingRequests, c.RateLimiter.remaining,
+			metric.WithAttributes(
+				attribute.String("type", "static"),
+				attribute.String("agent", c.AgentSymbol),
+			))
+		resetTime := c.RateLimiter.resetTime
+		if !resetTime.IsZero() {
+			o.ObserveFloat64(c.resetTimeGauge, time.Until(resetTime).Seconds(),
 				metric.WithAttributes(
-					attribute.String("type", "static"),
-					attribute.String("agent", client.AgentSymbol),
+					attribute.String("agent", c.AgentSymbol),
 				))
-			o.ObserveInt64(client.remainingRequests, client.RateLimiter.remaining,
+		}
+
+		// Queue metrics
+		if c.requestQueue != nil {
+			// Queue length
+			o.ObserveInt64(c.queueLengthGauge, int64(c.requestQueue.QueueLength()),
 				metric.WithAttributes(
-					attribute.String("type", "static"),
-					attribute.String("agent", client.AgentSymbol),
+					attribute.String("agent", c.AgentSymbol),
 				))
-			resetTime := client.RateLimiter.resetTime
-			if !resetTime.IsZero() {
-				o.ObserveFloat64(client.resetTimeGauge, time.Until(resetTime).Seconds(),
-					metric.WithAttributes(
-						attribute.String("agent", client.AgentSymbol),
-					))
-			}
 
-			// Queue metrics
-			if client.requestQueue != nil {
-				// Queue length
-				o.ObserveInt64(client.queueLengthGauge, int64(client.requestQueue.QueueLength()),
-					metric.WithAttributes(
-						attribute.String("agent", client.AgentSymbol),
-					))
-
-				// Average queue and process times
-				avgQueueTime, avgProcessTime, _ := client.requestQueue.GetMetrics()
-				o.ObserveFloat64(client.avgQueueTimeGauge, avgQueueTime.Seconds(),
-					metric.WithAttributes(
-						attribute.String("agent", client.AgentSymbol),
-					))
-				o.ObserveFloat64(client.avgProcessTimeGauge, avgProcessTime.Seconds(),
-					metric.WithAttributes(
-						attribute.String("agent", client.AgentSymbol),
-					))
-			}
-
-			return nil
-		}, client.rateLimitGauge, client.remainingRequests, client.resetTimeGauge,
-			client.queueLengthGauge, client.avgQueueTimeGauge, client.avgProcessTimeGauge)
-		if err != nil {
-			return nil, fmt.Errorf("failed to register metric callbacks: %w", err)
+			// Average queue and process times
+			avgQueueTime, avgProcessTime, _ := c.requestQueue.GetMetrics()
+			o.ObserveFloat64(c.avgQueueTimeGauge, avgQueueTime.Seconds(),
+				metric.WithAttributes(
+					attribute.String("agent", c.AgentSymbol),
+				))
+			o.ObserveFloat64(c.avgProcessTimeGauge, avgProcessTime.Seconds(),
+				metric.WithAttributes(
+					attribute.String("agent", c.AgentSymbol),
+				))
 		}
-	}
-
-	if apiError := client.getOrRegisterToken(options.Faction, options.Symbol, options.Email); apiError != nil {
-		return nil, apiError
-	}
 
-	// Initialize the request queue
-	queueSize := options.RequestQueueSize
-	if queueSize <= 0 {
-		queueSize = 100 // Default size
+		return nil
+	}, c.rateLimitGauge, c.remainingRequests, c.resetTimeGauge,
+		c.queueLengthGauge, c.avgQueueTimeGauge, c.avgProcessTimeGauge)
+	if err != nil {
+		return fmt.Errorf("failed to register metric callbacks: %w", err)
 	}
-	client.requestQueue = NewRequestQueue(client.context, client, queueSize)
+	c.metricsRegistration = registration
 
-	client.Logger.Info("New SpaceTraders client initialized",
-		"baseURL", client.baseURL,
-		"rateLimit", options.RequestsPerSecond,
-		"queueSize", queueSize)
-	return client, nil
+	return nil
 }
 
-// Get sends a GET request to the specified endpoint with optional query parameters
+// Get sends a GET request to the specified endpoint with optional query parameters.
+// Responses may be answered from the cache, as set by ClientOptions.CachePolicy.
 func (c *Client) Get(endpoint string, queryParams map[string]string, result interface{}) *models.APIError {
-	return c.requestQueue.Enqueue("GET", endpoint, nil, queryParams, result)
+	return c.cachedGet(context.Background(), endpoint, queryParams, result)
 }
 
 // Post sends a POST request to the specified endpoint with optional query parameters
@@ -530,7 +873,7 @@ func (c *Client) Patch(endpoint string, body interface{}, queryParams map[string
 // GetWithContext sends a GET request with context for metric labeling.
 // Use WithMetricLabels to add custom labels to the context.
 func (c *Client) GetWithContext(ctx context.Context, endpoint string, queryParams map[string]string, result interface{}) *models.APIError {
-	return c.requestQueue.EnqueueWithContext(ctx, "GET", endpoint, nil, queryParams, result)
+	return c.cachedGet(ctx, endpoint, queryParams, result)
 }
 
 // PostWithContext sends a POST request with context for metric labeling.
@@ -559,14 +902,42 @@ func (c *Client) PatchWithContext(ctx context.Context, endpoint string, body int
 
 // executeRequest executes an HTTP request with the given parameters
 // This is used by the request queue to process requests
-func (c *Client) executeRequest(ctx context.Context, method, endpoint string, body interface{}, queryParams map[string]string, result interface{}) *models.APIError {
+func (c *Client) executeRequest(ctx context.Context, method, endpoint string, body interface{}, queryParams map[string]string, result interface{}) (apiErr *models.APIError) {
 	startTime := time.Now()
 
+	// Each attempt gets its own span, a child of the request span started when the request was enqueued
+	ctx, span := tracer().Start(ctx, spanHTTP,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("agent", c.AgentSymbol),
+			attribute.String("http.request.method", method),
+			attribute.String("spacetraders.endpoint", c.redactor.String(endpoint)),
+			attribute.Bool("spacetraders.dry_run", c.dryRun != nil),
+		),
+	)
+	defer func() {
+		endSpanWithError(span, apiErr, c.redactor)
+	}()
+
+	if requiresAuth(method, endpoint) {
+		if authErr := c.ensureAuthenticated(); authErr != nil {
+			return authErr
+		}
+	}
+
+	token, ok := ctx.Value(requestTokenKey{}).(string)
+	if !ok {
+		token = c.currentToken()
+	}
 	request := c.httpClient.R().
+		SetContext(ctx).
 		SetHeader("Accept", "application/json").
-		SetAuthToken(c.token).
+		SetAuthToken(token).
 		SetResult(result)
 
+	// Propagate the trace context to the API using the W3C headers
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+
 	// For POST/PUT/PATCH requests, always send a body (empty object if nil)
 	// The SpaceTraders API requires {} instead of empty string for JSON endpoints
 	if body != nil {
@@ -584,13 +955,38 @@ func (c *Client) executeRequest(ctx context.Context, method, endpoint string, bo
 	var err error
 	var rateLimit *RateLimitResponse
 
-	// Wait for rate limit token - this will block until we can make the request
-	if err := c.RateLimiter.Wait(c.context); err != nil {
-		c.Logger.Error("Client Log: Rate limiter error", "error", err)
+	// In dry-run mode mutating requests are answered locally and never reach the API
+	if c.dryRun != nil && method != "GET" {
+		apiError = c.dryRun.simulate(method, endpoint, body, result)
+		if apiError != nil {
+			c.Logger.Warn("Dry run: request rejected", "method", method, "endpoint", endpoint, "error", apiError.Message)
+			return apiError
+		}
+		c.Logger.Info("Dry run: simulated request", "method", method, "endpoint", endpoint, "body", body)
+		if c.journal != nil {
+			response, _ := json.Marshal(result)
+			c.recordJournal(ctx, method, endpoint, body, response)
+		}
+		return nil
+	}
+
+	// Wait for rate limit token - this will block until we can make the request. A request
+	// that times out or is cancelled meanwhile gives up its place, as does a closing client.
+	waitCtx, cancelWait := context.WithCancel(ctx)
+	stopWait := context.AfterFunc(c.context, cancelWait)
+	err = c.RateLimiter.Wait(waitCtx)
+	stopWait()
+	cancelWait()
+	if err != nil {
+		if ctx.Err() != nil {
+			return contextError(ctx)
+		}
+		c.Logger.Error("Client Log: Rate limiter error", "method", method, "endpoint", endpoint, "error", err)
 		return &models.APIError{Message: err.Error(), Code: 429}
 	}
 
 	// Make the request
+	sentAt := time.Now()
 	resp, err = request.Execute(method, c.baseURL+endpoint)
 	duration := time.Since(startTime)
 
@@ -616,11 +1012,30 @@ func (c *Client) executeRequest(ctx context.Context, method, endpoint string, bo
 		}
 	}
 
+	if err != nil && isTimeout(err) {
+		statusCode = http.StatusRequestTimeout
+	}
+	c.recordSlowRequest(ctx, method, endpoint, time.Since(sentAt))
+
+	span.SetAttributes(attribute.Int("http.response.status_code", statusCode))
+	if rateLimit != nil {
+		span.SetAttributes(attribute.Int64("spacetraders.rate_limit.remaining", rateLimit.Remaining))
+	}
+
 	// Record metrics with custom labels from context
 	c.recordMetrics(ctx, method, endpoint, duration, statusCode, err, rateLimit)
 
 	// If successful, return immediately
 	if err == nil && !resp.IsError() {
+		if c.dryRun != nil {
+			c.dryRun.observe(endpoint, resp.Body())
+		}
+		if method == "GET" {
+			c.cacheResponse(endpoint, queryParams, resp.Body())
+		} else {
+			c.invalidateCache(method, endpoint, body, resp.Body())
+		}
+		c.recordJournal(ctx, method, endpoint, body, resp.Body())
 		return nil
 	}
 
@@ -675,6 +1090,8 @@ func (c *Client) executeRequest(ctx context.Context, method, endpoint string, bo
 	if resp != nil && resp.IsError() {
 		apiError = parseAPIError(resp)
 		c.Logger.Error("Client Log: API Request resulted in error",
+			"method", method,
+			"endpoint", endpoint,
 			"error", apiError.Error(),
 			"data", apiError.Data)
 
@@ -683,13 +1100,10 @@ func (c *Client) executeRequest(ctx context.Context, method, endpoint string, bo
 			c.Logger.Error("GAME RESET DETECTED: Token version mismatch",
 				"message", apiError.Message)
 
-			// Send notification through the game reset channel (non-blocking)
-			select {
-			case c.GameResetCh <- struct{}{}:
-				// Successfully sent notification
-			default:
-				// Channel buffer is full, which means a notification has already been sent
-				// This is fine, we just want to ensure at least one notification is sent
+			// Either recover and retry once, or notify through the game reset channel
+			if c.handleGameReset(ctx, token) {
+				retryCtx := context.WithValue(ctx, resetRetryKey{}, true)
+				return c.executeRequest(retryCtx, method, endpoint, body, queryParams, result)
 			}
 		}
 
@@ -783,13 +1197,63 @@ func parseAPIError(resp *resty.Response) *models.APIError {
 	}
 }
 
+// MetricsHandler returns an http.Handler serving the client's metrics in the Prometheus
+// text format. It returns nil unless telemetry uses the Prometheus metric exporter.
+func (c *Client) MetricsHandler() http.Handler {
+	if c.telemetryProviders == nil {
+		return nil
+	}
+	return c.telemetryProviders.MetricsHandler
+}
+
+// InMemoryTelemetry returns the telemetry recorded by signals using the in-memory exporter.
+// It returns nil unless at least one signal uses telemetry.ExporterMemory.
+func (c *Client) InMemoryTelemetry() *telemetry.InMemoryExporter {
+	if c.telemetryProviders == nil {
+		return nil
+	}
+	return c.telemetryProviders.InMemory
+}
+
+// Redactor returns the redactor applied to everything the client logs, journals or traces
+func (c *Client) Redactor() *Redactor {
+	return c.redactor
+}
+
+// RetryPolicy returns the policy the client retries rate-limited requests under
+func (c *Client) RetryPolicy() RetryPolicy {
+	return c.requestQueue.retryPolicy
+}
+
+// Meter returns the meter used for the client's metrics, or nil if metrics are disabled.
+// Use it to register additional instruments alongside the built-in ones.
+func (c *Client) Meter() metric.Meter {
+	return c.meter
+}
+
 // GetToken returns the current token used by the client
 func (c *Client) GetToken() string {
-	return c.token
+	return c.currentToken()
 }
 
 // SetToken sets the token for the client
 func (c *Client) SetToken(token string) {
+	c.setToken(token)
+}
+
+// currentToken returns the token sent with requests
+func (c *Client) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.token
+}
+
+// setToken publishes the token sent with requests, redacting it from logs
+func (c *Client) setToken(token string) {
+	c.redactor.AddSecret(token)
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
 	c.token = token
 }
 
@@ -829,13 +1293,38 @@ func (c *Client) WaitForGameReset(ctx context.Context) bool {
 	}
 }
 
-// Close gracefully shuts down the client and its telemetry providers
+// Close gracefully shuts down the client and its telemetry providers.
+// Clients created by an AgentManager leave the shared telemetry and journal open.
 func (c *Client) Close(ctx context.Context) error {
 	// Shutdown the request queue first
 	if c.requestQueue != nil {
 		c.requestQueue.Shutdown()
 	}
 
+	if c.metricsRegistration != nil {
+		if err := c.metricsRegistration.Unregister(); err != nil {
+			c.Logger.Warn("Failed to unregister metric callbacks", "error", err)
+		}
+	}
+
+	// Stop the cache janitor and unregister the cache metrics
+	if c.memoryCache != nil {
+		if err := c.memoryCache.Close(); err != nil {
+			c.Logger.Warn("Failed to close cache", "error", err)
+		}
+	}
+
+	// The journal and telemetry of managed clients are closed by their AgentManager
+	if c.managed {
+		return nil
+	}
+
+	if c.journal != nil {
+		if err := c.journal.Close(); err != nil {
+			c.Logger.Warn("Failed to close journal", "error", err)
+		}
+	}
+
 	// Then shutdown telemetry
 	if c.telemetryProviders != nil {
 		return c.telemetryProviders.Shutdown(ctx)
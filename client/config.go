@@ -0,0 +1,646 @@
+package client
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/telemetry"
+	"gopkg.in/yaml.v3"
+)
+
+// Token store types accepted in config files and SPACETRADERS_TOKENSTORE
+const (
+	TokenStoreFile      = "file"
+	TokenStoreEncrypted = "encrypted"
+	TokenStoreEnv       = "env"
+	TokenStoreMemory    = "memory"
+)
+
+// fileConfig is the layout of a client config file. Durations are strings such as "500ms".
+type fileConfig struct {
+	BaseURL           string           `yaml:"baseUrl" json:"baseUrl"`
+	Symbol            string           `yaml:"symbol" json:"symbol"`
+	Faction           string           `yaml:"faction" json:"faction"`
+	Email             string           `yaml:"email" json:"email"`
+	AuthMode          string           `yaml:"authMode" json:"authMode"`
+	LogLevel          string           `yaml:"logLevel" json:"logLevel"`
+	RequestsPerSecond *float32         `yaml:"requestsPerSecond" json:"requestsPerSecond"`
+	RateLimitStrategy string           `yaml:"rateLimitStrategy" json:"rateLimitStrategy"`
+	RetryDelay        string           `yaml:"retryDelay" json:"retryDelay"`
+	RequestQueueSize  *int             `yaml:"requestQueueSize" json:"requestQueueSize"`
+	DryRun            *bool            `yaml:"dryRun" json:"dryRun"`
+	AutoRecoverReset  *bool            `yaml:"autoRecoverReset" json:"autoRecoverReset"`
+	RedactionPatterns []string         `yaml:"redactionPatterns" json:"redactionPatterns"`
+	CacheMaxEntries   *int             `yaml:"cacheMaxEntries" json:"cacheMaxEntries"`
+	CacheDir          string           `yaml:"cacheDir" json:"cacheDir"`
+	Retry             *retryConfig     `yaml:"retry" json:"retry"`
+	TokenStore        *tokenConfig     `yaml:"tokenStore" json:"tokenStore"`
+	Telemetry         *telemetryConfig `yaml:"telemetry" json:"telemetry"`
+	Transport         *transportConfig `yaml:"transport" json:"transport"`
+}
+
+type retryConfig struct {
+	MaxRetries     *int   `yaml:"maxRetries" json:"maxRetries"`
+	InitialBackoff string `yaml:"initialBackoff" json:"initialBackoff"`
+	MaxBackoff     string `yaml:"maxBackoff" json:"maxBackoff"`
+	Jitter         string `yaml:"jitter" json:"jitter"`
+}
+
+type transportConfig struct {
+	RequestTimeout       string `yaml:"requestTimeout" json:"requestTimeout"`
+	Timeout              string `yaml:"timeout" json:"timeout"`
+	SlowRequestThreshold string `yaml:"slowRequestThreshold" json:"slowRequestThreshold"`
+	ProxyURL             string `yaml:"proxyUrl" json:"proxyUrl"`
+	InsecureSkipVerify   *bool  `yaml:"insecureSkipVerify" json:"insecureSkipVerify"`
+	DialTimeout          string `yaml:"dialTimeout" json:"dialTimeout"`
+	TLSHandshakeTimeout  string `yaml:"tlsHandshakeTimeout" json:"tlsHandshakeTimeout"`
+	KeepAlive            string `yaml:"keepAlive" json:"keepAlive"`
+	DisableKeepAlives    *bool  `yaml:"disableKeepAlives" json:"disableKeepAlives"`
+	IdleConnTimeout      string `yaml:"idleConnTimeout" json:"idleConnTimeout"`
+	MaxIdleConns         *int   `yaml:"maxIdleConns" json:"maxIdleConns"`
+	MaxIdleConnsPerHost  *int   `yaml:"maxIdleConnsPerHost" json:"maxIdleConnsPerHost"`
+	MaxConnsPerHost      *int   `yaml:"maxConnsPerHost" json:"maxConnsPerHost"`
+	UserAgent            string `yaml:"userAgent" json:"userAgent"`
+}
+
+type tokenConfig struct {
+	Type       string `yaml:"type" json:"type"`
+	Path       string `yaml:"path" json:"path"`
+	Passphrase string `yaml:"passphrase" json:"passphrase"`
+}
+
+type telemetryConfig struct {
+	ServiceName          string            `yaml:"serviceName" json:"serviceName"`
+	ServiceVersion       string            `yaml:"serviceVersion" json:"serviceVersion"`
+	Environment          string            `yaml:"environment" json:"environment"`
+	OTLPEndpoint         string            `yaml:"otlpEndpoint" json:"otlpEndpoint"`
+	OTLPHTTPEndpoint     string            `yaml:"otlpHttpEndpoint" json:"otlpHttpEndpoint"`
+	MetricExporter       string            `yaml:"metricExporter" json:"metricExporter"`
+	TraceExporter        string            `yaml:"traceExporter" json:"traceExporter"`
+	LogExporter          string            `yaml:"logExporter" json:"logExporter"`
+	PrometheusAddr       string            `yaml:"prometheusAddr" json:"prometheusAddr"`
+	MetricInterval       string            `yaml:"metricInterval" json:"metricInterval"`
+	TraceSampleRate      *float64          `yaml:"traceSampleRate" json:"traceSampleRate"`
+	EnableMetrics        *bool             `yaml:"enableMetrics" json:"enableMetrics"`
+	EnableTracing        *bool             `yaml:"enableTracing" json:"enableTracing"`
+	EnableLogging        *bool             `yaml:"enableLogging" json:"enableLogging"`
+	AdditionalAttributes map[string]string `yaml:"additionalAttributes" json:"additionalAttributes"`
+}
+
+// LoadClientOptions builds ClientOptions from DefaultClientOptions, the config file at path
+// and SPACETRADERS_* environment variables, in that order of precedence. The file may be
+// YAML (.yaml, .yml) or JSON (.json); an empty path reads the environment only. Unknown
+// keys in the file, unknown SPACETRADERS_* variables and invalid values are reported as errors.
+func LoadClientOptions(path string) (ClientOptions, error) {
+	options := DefaultClientOptions()
+
+	var tokens *tokenConfig
+	if path != "" {
+		config, err := readConfigFile(path)
+		if err != nil {
+			return ClientOptions{}, err
+		}
+		if err := config.apply(&options); err != nil {
+			return ClientOptions{}, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		tokens = config.TokenStore
+	}
+
+	if err := checkEnv(); err != nil {
+		return ClientOptions{}, err
+	}
+	if err := applyEnv(&options); err != nil {
+		return ClientOptions{}, err
+	}
+
+	// The token store is built once from the file's settings and the environment's overrides,
+	// so that a path or passphrase alone does not replace the configured store type
+	if tokens, fromEnv := tokenEnv(tokens); tokens != nil {
+		store, err := newConfiguredTokenStore(tokens.Type, tokens.Path, tokens.Passphrase)
+		switch {
+		case err != nil && fromEnv:
+			return ClientOptions{}, envError("TOKENSTORE", err)
+		case err != nil:
+			return ClientOptions{}, fmt.Errorf("invalid config file %s: tokenStore: %w", path, err)
+		}
+		options.TokenStore = store
+	}
+
+	if err := validateClientOptions(options); err != nil {
+		return ClientOptions{}, err
+	}
+	return options, nil
+}
+
+// readConfigFile decodes the config file at path, rejecting unknown keys
+func readConfigFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	config := &fileConfig{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file extension %q: use .yaml, .yml or .json", ext)
+	}
+	return config, nil
+}
+
+// apply copies the values set in the config file onto options
+func (f *fileConfig) apply(options *ClientOptions) error {
+	setString(&options.BaseURL, f.BaseURL)
+	setString(&options.Symbol, f.Symbol)
+	setString(&options.Faction, f.Faction)
+	setString(&options.Email, f.Email)
+
+	if f.AuthMode != "" {
+		options.AuthMode = AuthMode(f.AuthMode)
+	}
+	if f.RateLimitStrategy != "" {
+		options.RateLimitStrategy = RateLimitStrategy(f.RateLimitStrategy)
+	}
+	if f.LogLevel != "" {
+		if err := options.LogLevel.UnmarshalText([]byte(f.LogLevel)); err != nil {
+			return fmt.Errorf("logLevel: %w", err)
+		}
+	}
+	if f.RequestsPerSecond != nil {
+		options.RequestsPerSecond = *f.RequestsPerSecond
+	}
+	if err := setDuration(&options.RetryDelay, f.RetryDelay, "retryDelay"); err != nil {
+		return err
+	}
+	if f.RequestQueueSize != nil {
+		options.RequestQueueSize = *f.RequestQueueSize
+	}
+	if f.DryRun != nil {
+		options.DryRun = *f.DryRun
+	}
+	if f.AutoRecoverReset != nil {
+		options.AutoRecoverReset = *f.AutoRecoverReset
+	}
+	if len(f.RedactionPatterns) > 0 {
+		options.RedactionPatterns = f.RedactionPatterns
+	}
+	if f.CacheMaxEntries != nil {
+		options.CacheMaxEntries = *f.CacheMaxEntries
+	}
+	setString(&options.CacheDir, f.CacheDir)
+
+	if f.Retry != nil {
+		policy := retryPolicy(*options)
+		if f.Retry.MaxRetries != nil {
+			policy.MaxRetries = *f.Retry.MaxRetries
+		}
+		if err := setDuration(&policy.InitialBackoff, f.Retry.InitialBackoff, "retry.initialBackoff"); err != nil {
+			return err
+		}
+		if err := setDuration(&policy.MaxBackoff, f.Retry.MaxBackoff, "retry.maxBackoff"); err != nil {
+			return err
+		}
+		if err := setDuration(&policy.Jitter, f.Retry.Jitter, "retry.jitter"); err != nil {
+			return err
+		}
+		options.RetryPolicy = &policy
+	}
+
+	if t := f.Telemetry; t != nil {
+		to := telemetryOptions(options)
+		setString(&to.ServiceName, t.ServiceName)
+		setString(&to.ServiceVersion, t.ServiceVersion)
+		setString(&to.Environment, t.Environment)
+		setString(&to.OTLPEndpoint, t.OTLPEndpoint)
+		setString(&to.OTLPHTTPEndpoint, t.OTLPHTTPEndpoint)
+		setString(&to.PrometheusAddr, t.PrometheusAddr)
+		setExporter(&to.MetricExporter, t.MetricExporter)
+		setExporter(&to.TraceExporter, t.TraceExporter)
+		setExporter(&to.LogExporter, t.LogExporter)
+		if err := setDuration(&to.MetricInterval, t.MetricInterval, "telemetry.metricInterval"); err != nil {
+			return err
+		}
+		if t.TraceSampleRate != nil {
+			to.TraceSampleRate = *t.TraceSampleRate
+		}
+		if t.EnableMetrics != nil {
+			to.EnableMetrics = *t.EnableMetrics
+		}
+		if t.EnableTracing != nil {
+			to.EnableTracing = *t.EnableTracing
+		}
+		if t.EnableLogging != nil {
+			to.EnableLogging = *t.EnableLogging
+		}
+		if len(t.AdditionalAttributes) > 0 {
+			to.AdditionalAttributes = t.AdditionalAttributes
+		}
+	}
+
+	if t := f.Transport; t != nil {
+		to := transportOptions(options)
+		setString(&to.ProxyURL, t.ProxyURL)
+		setString(&to.UserAgent, t.UserAgent)
+		for key, d := range map[string]struct {
+			dst   *time.Duration
+			value string
+		}{
+			"transport.requestTimeout":       {&to.RequestTimeout, t.RequestTimeout},
+			"transport.timeout":              {&to.Timeout, t.Timeout},
+			"transport.slowRequestThreshold": {&to.SlowRequestThreshold, t.SlowRequestThreshold},
+			"transport.dialTimeout":          {&to.DialTimeout, t.DialTimeout},
+			"transport.tlsHandshakeTimeout":  {&to.TLSHandshakeTimeout, t.TLSHandshakeTimeout},
+			"transport.keepAlive":            {&to.KeepAlive, t.KeepAlive},
+			"transport.idleConnTimeout":      {&to.IdleConnTimeout, t.IdleConnTimeout},
+		} {
+			if err := setDuration(d.dst, d.value, key); err != nil {
+				return err
+			}
+		}
+		if t.InsecureSkipVerify != nil {
+			if to.TLSConfig == nil {
+				to.TLSConfig = &tls.Config{}
+			}
+			to.TLSConfig.InsecureSkipVerify = *t.InsecureSkipVerify
+		}
+		if t.DisableKeepAlives != nil {
+			to.DisableKeepAlives = *t.DisableKeepAlives
+		}
+		if t.MaxIdleConns != nil {
+			to.MaxIdleConns = *t.MaxIdleConns
+		}
+		if t.MaxIdleConnsPerHost != nil {
+			to.MaxIdleConnsPerHost = *t.MaxIdleConnsPerHost
+		}
+		if t.MaxConnsPerHost != nil {
+			to.MaxConnsPerHost = *t.MaxConnsPerHost
+		}
+	}
+
+	return nil
+}
+
+// env returns the SPACETRADERS_ environment variable name, and false if it is unset or empty
+func env(name string) (string, bool) {
+	value, ok := os.LookupEnv("SPACETRADERS_" + name)
+	return value, ok && value != ""
+}
+
+// envError reports an invalid SPACETRADERS_ environment variable
+func envError(name string, err error) error {
+	return fmt.Errorf("invalid SPACETRADERS_%s: %w", name, err)
+}
+
+// applyEnv overrides options with the SPACETRADERS_* environment variables that are set
+func applyEnv(options *ClientOptions) error {
+	if v, ok := env("BASE_URL"); ok {
+		options.BaseURL = v
+	}
+	if v, ok := env("SYMBOL"); ok {
+		options.Symbol = v
+	}
+	if v, ok := env("FACTION"); ok {
+		options.Faction = v
+	}
+	if v, ok := env("EMAIL"); ok {
+		options.Email = v
+	}
+	if v, ok := env("AUTH_MODE"); ok {
+		options.AuthMode = AuthMode(v)
+	}
+	if v, ok := env("LOG_LEVEL"); ok {
+		if err := options.LogLevel.UnmarshalText([]byte(v)); err != nil {
+			return envError("LOG_LEVEL", err)
+		}
+	}
+	if v, ok := env("REQUESTS_PER_SECOND"); ok {
+		rps, err := strconv.ParseFloat(v, 32)
+		if err != nil {
+			return envError("REQUESTS_PER_SECOND", err)
+		}
+		options.RequestsPerSecond = float32(rps)
+	}
+	if v, ok := env("RATE_LIMIT_STRATEGY"); ok {
+		options.RateLimitStrategy = RateLimitStrategy(v)
+	}
+	if v, ok := env("QUEUE_SIZE"); ok {
+		size, err := strconv.Atoi(v)
+		if err != nil {
+			return envError("QUEUE_SIZE", err)
+		}
+		options.RequestQueueSize = size
+	}
+	if v, ok := env("DRY_RUN"); ok {
+		dryRun, err := strconv.ParseBool(v)
+		if err != nil {
+			return envError("DRY_RUN", err)
+		}
+		options.DryRun = dryRun
+	}
+	if v, ok := env("AUTO_RECOVER_RESET"); ok {
+		autoRecover, err := strconv.ParseBool(v)
+		if err != nil {
+			return envError("AUTO_RECOVER_RESET", err)
+		}
+		options.AutoRecoverReset = autoRecover
+	}
+	if v, ok := env("CACHE_DIR"); ok {
+		options.CacheDir = v
+	}
+
+	if v, ok := env("MAX_RETRIES"); ok {
+		retries, err := strconv.Atoi(v)
+		if err != nil {
+			return envError("MAX_RETRIES", err)
+		}
+		policy := retryPolicy(*options)
+		policy.MaxRetries = retries
+		options.RetryPolicy = &policy
+	}
+	if v, ok := env("RETRY_BACKOFF"); ok {
+		backoff, err := time.ParseDuration(v)
+		if err != nil {
+			return envError("RETRY_BACKOFF", err)
+		}
+		policy := retryPolicy(*options)
+		policy.InitialBackoff = backoff
+		options.RetryPolicy = &policy
+	}
+
+	if v, ok := env("PROXY_URL"); ok {
+		transportOptions(options).ProxyURL = v
+	}
+	if v, ok := env("USER_AGENT"); ok {
+		transportOptions(options).UserAgent = v
+	}
+	if v, ok := env("REQUEST_TIMEOUT"); ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return envError("REQUEST_TIMEOUT", err)
+		}
+		transportOptions(options).RequestTimeout = timeout
+	}
+	if v, ok := env("TIMEOUT"); ok {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return envError("TIMEOUT", err)
+		}
+		transportOptions(options).Timeout = timeout
+	}
+
+	// Exporters and endpoints enable telemetry; the service name and environment only
+	// describe telemetry that is enabled by them or the config file
+	telemetryEnv := map[string]func(*TelemetryOptions, string){
+		"OTLP_ENDPOINT":      func(t *TelemetryOptions, v string) { t.OTLPEndpoint = v },
+		"OTLP_HTTP_ENDPOINT": func(t *TelemetryOptions, v string) { t.OTLPHTTPEndpoint = v },
+		"METRIC_EXPORTER":    func(t *TelemetryOptions, v string) { t.MetricExporter = telemetry.Exporter(v) },
+		"TRACE_EXPORTER":     func(t *TelemetryOptions, v string) { t.TraceExporter = telemetry.Exporter(v) },
+		"LOG_EXPORTER":       func(t *TelemetryOptions, v string) { t.LogExporter = telemetry.Exporter(v) },
+		"PROMETHEUS_ADDR":    func(t *TelemetryOptions, v string) { t.PrometheusAddr = v },
+	}
+	for name, set := range telemetryEnv {
+		if v, ok := env(name); ok {
+			set(telemetryOptions(options), v)
+		}
+	}
+	if t := options.TelemetryOptions; t != nil {
+		if v, ok := env("SERVICE_NAME"); ok {
+			t.ServiceName = v
+		}
+		if v, ok := env("ENVIRONMENT"); ok {
+			t.Environment = v
+		}
+	}
+
+	return nil
+}
+
+// envVariables lists the SPACETRADERS_* environment variables read by LoadClientOptions
+var envVariables = []string{
+	"BASE_URL", "SYMBOL", "FACTION", "EMAIL", "AUTH_MODE",
+	"LOG_LEVEL", "DRY_RUN", "AUTO_RECOVER_RESET", "CACHE_DIR",
+	"REQUESTS_PER_SECOND", "RATE_LIMIT_STRATEGY", "QUEUE_SIZE",
+	"MAX_RETRIES", "RETRY_BACKOFF",
+	"PROXY_URL", "USER_AGENT", "REQUEST_TIMEOUT", "TIMEOUT",
+	"TOKENSTORE", "TOKENSTORE_PATH", "TOKENSTORE_PASSPHRASE",
+	"OTLP_ENDPOINT", "OTLP_HTTP_ENDPOINT", "METRIC_EXPORTER", "TRACE_EXPORTER", "LOG_EXPORTER",
+	"PROMETHEUS_ADDR", "SERVICE_NAME", "ENVIRONMENT",
+}
+
+// checkEnv reports SPACETRADERS_* environment variables that are neither options nor tokens
+// read by EnvTokenStore, such as misspelled options, like unknown keys in a config file
+func checkEnv() error {
+	known := make(map[string]bool, len(envVariables))
+	for _, name := range envVariables {
+		known["SPACETRADERS_"+name] = true
+	}
+
+	var unknown []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(name, "SPACETRADERS_") || known[name] || name == envAccountToken ||
+			strings.HasPrefix(name, envTokenPrefix) || strings.HasPrefix(name, envResetDatePrefix) {
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown environment variables %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// validateClientOptions reports the first invalid value in options
+func validateClientOptions(options ClientOptions) error {
+	switch options.AuthMode {
+	case "", AuthEager, AuthLazy, AuthAnonymous:
+	default:
+		return fmt.Errorf("invalid auth mode %q: must be %s, %s or %s", options.AuthMode, AuthEager, AuthLazy, AuthAnonymous)
+	}
+	if options.Symbol == "" && options.AuthMode != AuthAnonymous {
+		return fmt.Errorf("symbol is required unless the auth mode is %s", AuthAnonymous)
+	}
+
+	switch options.RateLimitStrategy {
+	case "", RateLimitAdaptive, RateLimitFixed:
+	default:
+		return fmt.Errorf("invalid rate limit strategy %q: must be %s or %s", options.RateLimitStrategy, RateLimitAdaptive, RateLimitFixed)
+	}
+	if options.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests per second must not be negative")
+	}
+	if options.RequestQueueSize < 0 {
+		return fmt.Errorf("request queue size must not be negative")
+	}
+	if options.RetryDelay < 0 {
+		return fmt.Errorf("retry delay must not be negative")
+	}
+
+	if policy := options.RetryPolicy; policy != nil {
+		if policy.MaxRetries < 0 || policy.InitialBackoff < 0 || policy.MaxBackoff < 0 || policy.Jitter < 0 {
+			return fmt.Errorf("retry policy values must not be negative")
+		}
+	}
+
+	if t := options.Transport; t != nil {
+		if t.RequestTimeout < 0 || t.Timeout < 0 || t.DialTimeout < 0 || t.TLSHandshakeTimeout < 0 ||
+			t.IdleConnTimeout < 0 || t.MaxIdleConns < 0 || t.MaxIdleConnsPerHost < 0 || t.MaxConnsPerHost < 0 {
+			return fmt.Errorf("transport timeouts and pool sizes must not be negative")
+		}
+		if t.ProxyURL != "" {
+			if proxyURL, err := url.Parse(t.ProxyURL); err != nil || proxyURL.Host == "" {
+				return fmt.Errorf("invalid proxy URL %q", t.ProxyURL)
+			}
+		}
+	}
+
+	if t := options.TelemetryOptions; t != nil {
+		for signal, exporter := range map[string]telemetry.Exporter{
+			"metric": t.MetricExporter,
+			"trace":  t.TraceExporter,
+			"log":    t.LogExporter,
+		} {
+			switch exporter {
+			case "", telemetry.ExporterOTLP, telemetry.ExporterOTLPHTTP, telemetry.ExporterStdout, telemetry.ExporterMemory:
+			case telemetry.ExporterPrometheus:
+				if signal != "metric" {
+					return fmt.Errorf("the %s exporter only supports metrics", exporter)
+				}
+			default:
+				return fmt.Errorf("invalid %s exporter %q", signal, exporter)
+			}
+		}
+		if t.TraceSampleRate < 0 || t.TraceSampleRate > 1 {
+			return fmt.Errorf("trace sample rate must be between 0 and 1")
+		}
+		if t.MetricInterval < 0 {
+			return fmt.Errorf("metric interval must not be negative")
+		}
+	}
+
+	return nil
+}
+
+// tokenEnv returns the token store settings of the config file overridden by the
+// SPACETRADERS_TOKENSTORE* environment variables, and whether any of them were set
+func tokenEnv(file *tokenConfig) (*tokenConfig, bool) {
+	var tokens tokenConfig
+	if file != nil {
+		tokens = *file
+	}
+
+	fromEnv := false
+	for name, value := range map[string]*string{
+		"TOKENSTORE":            &tokens.Type,
+		"TOKENSTORE_PATH":       &tokens.Path,
+		"TOKENSTORE_PASSPHRASE": &tokens.Passphrase,
+	} {
+		if v, ok := env(name); ok {
+			*value = v
+			fromEnv = true
+		}
+	}
+
+	if file == nil && !fromEnv {
+		return nil, false
+	}
+	return &tokens, fromEnv
+}
+
+// newConfiguredTokenStore creates a token store from its config file or environment settings
+func newConfiguredTokenStore(storeType, path, passphrase string) (TokenStore, error) {
+	if path == "" {
+		path = DefaultTokenFile
+	}
+
+	switch storeType {
+	case "", TokenStoreFile:
+		return NewFileTokenStore(path), nil
+	case TokenStoreEncrypted:
+		if passphrase == "" {
+			return nil, fmt.Errorf("the %s token store requires a passphrase", TokenStoreEncrypted)
+		}
+		return NewEncryptedFileTokenStore(path, passphrase)
+	case TokenStoreEnv:
+		return NewEnvTokenStore(), nil
+	case TokenStoreMemory:
+		return NewMemoryTokenStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown token store type %q: must be %s, %s, %s or %s",
+			storeType, TokenStoreFile, TokenStoreEncrypted, TokenStoreEnv, TokenStoreMemory)
+	}
+}
+
+// retryPolicy returns a copy of the retry policy in options, or the default policy
+func retryPolicy(options ClientOptions) RetryPolicy {
+	if options.RetryPolicy != nil {
+		return *options.RetryPolicy
+	}
+	return DefaultRetryPolicy()
+}
+
+// telemetryOptions returns the telemetry options in options, enabling telemetry with the
+// defaults if it is not configured yet
+func telemetryOptions(options *ClientOptions) *TelemetryOptions {
+	if options.TelemetryOptions == nil {
+		options.TelemetryOptions = DefaultTelemetryOptions()
+	}
+	return options.TelemetryOptions
+}
+
+// transportOptions returns the transport options in options, starting from the defaults
+// if they are not configured yet
+func transportOptions(options *ClientOptions) *TransportOptions {
+	if options.Transport == nil {
+		options.Transport = DefaultTransportOptions()
+	}
+	return options.Transport
+}
+
+func setString(dst *string, value string) {
+	if value != "" {
+		*dst = value
+	}
+}
+
+func setExporter(dst *telemetry.Exporter, value string) {
+	if value != "" {
+		*dst = telemetry.Exporter(value)
+	}
+}
+
+func setDuration(dst *time.Duration, value, key string) error {
+	if value == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	*dst = d
+	return nil
+}
@@ -0,0 +1,261 @@
+package client
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadClientOptionsYAML(t *testing.T) {
+	tokens := filepath.Join(t.TempDir(), "tokens.json")
+	path := writeConfig(t, "client.yaml", `
+baseUrl: http://localhost:3000/v2
+symbol: TEST
+faction: COSMIC
+logLevel: debug
+requestsPerSecond: 1.5
+rateLimitStrategy: fixed
+requestQueueSize: 25
+cacheMaxEntries: 500
+cacheDir: .cache
+retry:
+  maxRetries: 5
+  initialBackoff: 250ms
+  maxBackoff: 10s
+tokenStore:
+  type: file
+  path: `+tokens+`
+telemetry:
+  serviceName: bot
+  otlpEndpoint: collector:4317
+  metricExporter: prometheus
+  prometheusAddr: ":9464"
+`)
+
+	options, err := LoadClientOptions(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:3000/v2", options.BaseURL)
+	assert.Equal(t, "TEST", options.Symbol)
+	assert.Equal(t, "COSMIC", options.Faction)
+	assert.Equal(t, slog.LevelDebug, options.LogLevel)
+	assert.Equal(t, float32(1.5), options.RequestsPerSecond)
+	assert.Equal(t, RateLimitFixed, options.RateLimitStrategy)
+	assert.Equal(t, 25, options.RequestQueueSize)
+	assert.Equal(t, 500, options.CacheMaxEntries)
+	assert.Equal(t, ".cache", options.CacheDir)
+	assert.Equal(t, time.Second, options.RetryDelay, "unset values keep their defaults")
+
+	require.NotNil(t, options.RetryPolicy)
+	assert.Equal(t, 5, options.RetryPolicy.MaxRetries)
+	assert.Equal(t, 250*time.Millisecond, options.RetryPolicy.InitialBackoff)
+	assert.Equal(t, 10*time.Second, options.RetryPolicy.MaxBackoff)
+	assert.Equal(t, DefaultRetryPolicy().Jitter, options.RetryPolicy.Jitter)
+
+	store, ok := options.TokenStore.(*FileTokenStore)
+	require.True(t, ok)
+	assert.Equal(t, tokens, store.path)
+
+	require.NotNil(t, options.TelemetryOptions)
+	assert.Equal(t, "bot", options.TelemetryOptions.ServiceName)
+	assert.Equal(t, "collector:4317", options.TelemetryOptions.OTLPEndpoint)
+	assert.Equal(t, telemetry.ExporterPrometheus, options.TelemetryOptions.MetricExporter)
+	assert.Equal(t, telemetry.ExporterOTLP, options.TelemetryOptions.TraceExporter)
+	assert.Equal(t, ":9464", options.TelemetryOptions.PrometheusAddr)
+}
+
+func TestLoadClientOptionsJSON(t *testing.T) {
+	path := writeConfig(t, "client.json", `{
+		"symbol": "TEST",
+		"authMode": "lazy",
+		"tokenStore": {"type": "memory"}
+	}`)
+
+	options, err := LoadClientOptions(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "TEST", options.Symbol)
+	assert.Equal(t, AuthLazy, options.AuthMode)
+	assert.Equal(t, DefaultClientOptions().BaseURL, options.BaseURL)
+	assert.IsType(t, &MemoryTokenStore{}, options.TokenStore)
+	assert.Nil(t, options.TelemetryOptions)
+}
+
+func TestLoadClientOptionsUnknownKeys(t *testing.T) {
+	_, err := LoadClientOptions(writeConfig(t, "client.yaml", "symbol: TEST\nrateLimit: 2\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rateLimit")
+
+	_, err = LoadClientOptions(writeConfig(t, "client.json", `{"symbol": "TEST", "retry": {"retries": 2}}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "retries")
+
+	_, err = LoadClientOptions(writeConfig(t, "client.toml", "symbol = 'TEST'"))
+	assert.ErrorContains(t, err, "unsupported config file extension")
+}
+
+func TestLoadClientOptionsValidation(t *testing.T) {
+	tests := map[string]string{
+		"symbol is required":          "faction: COSMIC\n",
+		"invalid rate limit strategy": "symbol: TEST\nrateLimitStrategy: bursty\n",
+		"invalid auth mode":           "symbol: TEST\nauthMode: sometimes\n",
+		"retryDelay":                  "symbol: TEST\nretryDelay: soon\n",
+		"must not be negative":        "symbol: TEST\nrequestQueueSize: -1\n",
+		"requires a passphrase":       "symbol: TEST\ntokenStore:\n  type: encrypted\n",
+		"unknown token store type":    "symbol: TEST\ntokenStore:\n  type: vault\n",
+		"invalid trace exporter":      "symbol: TEST\ntelemetry:\n  traceExporter: jaeger\n",
+		"only supports metrics":       "symbol: TEST\ntelemetry:\n  logExporter: prometheus\n",
+	}
+
+	for message, content := range tests {
+		t.Run(message, func(t *testing.T) {
+			_, err := LoadClientOptions(writeConfig(t, "client.yaml", content))
+			assert.ErrorContains(t, err, message)
+		})
+	}
+
+	options, err := LoadClientOptions(writeConfig(t, "client.yaml", "authMode: anonymous\n"))
+	require.NoError(t, err)
+	assert.Equal(t, AuthAnonymous, options.AuthMode)
+}
+
+func TestLoadClientOptionsEnvironment(t *testing.T) {
+	path := writeConfig(t, "client.yaml", "symbol: FILE\nrequestQueueSize: 25\n")
+
+	t.Setenv("SPACETRADERS_SYMBOL", "ENV")
+	t.Setenv("SPACETRADERS_QUEUE_SIZE", "50")
+	t.Setenv("SPACETRADERS_RATE_LIMIT_STRATEGY", "fixed")
+	t.Setenv("SPACETRADERS_MAX_RETRIES", "1")
+	t.Setenv("SPACETRADERS_TOKENSTORE", "env")
+	t.Setenv("SPACETRADERS_OTLP_ENDPOINT", "collector:4317")
+	t.Setenv("SPACETRADERS_CACHE_DIR", "/var/cache/bot")
+
+	options, err := LoadClientOptions(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "ENV", options.Symbol)
+	assert.Equal(t, 50, options.RequestQueueSize)
+	assert.Equal(t, RateLimitFixed, options.RateLimitStrategy)
+	require.NotNil(t, options.RetryPolicy)
+	assert.Equal(t, 1, options.RetryPolicy.MaxRetries)
+	assert.IsType(t, &EnvTokenStore{}, options.TokenStore)
+	require.NotNil(t, options.TelemetryOptions)
+	assert.Equal(t, "collector:4317", options.TelemetryOptions.OTLPEndpoint)
+	assert.Equal(t, "/var/cache/bot", options.CacheDir)
+
+	t.Setenv("SPACETRADERS_QUEUE_SIZE", "many")
+	_, err = LoadClientOptions(path)
+	assert.ErrorContains(t, err, "SPACETRADERS_QUEUE_SIZE")
+}
+
+func TestLoadClientOptionsTokenStoreEnvironment(t *testing.T) {
+	path := writeConfig(t, "client.yaml", "symbol: TEST\ntokenStore:\n  type: encrypted\n  path: file-tokens.enc\n  passphrase: from-file\n")
+
+	// A path alone keeps the file's encrypted store
+	t.Setenv("SPACETRADERS_TOKENSTORE_PATH", "env-tokens.enc")
+	options, err := LoadClientOptions(path)
+	require.NoError(t, err)
+	store, ok := options.TokenStore.(*FileTokenStore)
+	require.True(t, ok)
+	assert.Equal(t, "env-tokens.enc", store.path)
+	assert.Equal(t, "from-file", store.passphrase)
+
+	// A passphrase alone overrides the file's
+	t.Setenv("SPACETRADERS_TOKENSTORE_PATH", "")
+	t.Setenv("SPACETRADERS_TOKENSTORE_PASSPHRASE", "from-env")
+	options, err = LoadClientOptions(path)
+	require.NoError(t, err)
+	store, ok = options.TokenStore.(*FileTokenStore)
+	require.True(t, ok)
+	assert.Equal(t, "file-tokens.enc", store.path)
+	assert.Equal(t, "from-env", store.passphrase)
+
+	// The type is overridden too, and invalid combinations are reported for the environment
+	t.Setenv("SPACETRADERS_TOKENSTORE", "memory")
+	options, err = LoadClientOptions(path)
+	require.NoError(t, err)
+	assert.IsType(t, &MemoryTokenStore{}, options.TokenStore)
+
+	t.Setenv("SPACETRADERS_TOKENSTORE", "vault")
+	_, err = LoadClientOptions(path)
+	assert.ErrorContains(t, err, "SPACETRADERS_TOKENSTORE")
+}
+
+func TestFixedRateLimiterIgnoresAPILimits(t *testing.T) {
+	rl := newRateLimiter(RateLimitFixed, 1)
+	rl.updateLimits(10, 30, 5, time.Now().Add(time.Second))
+	assert.Equal(t, 1.0, float64(rl.staticLimiter.Limit()))
+
+	rl = newRateLimiter(RateLimitAdaptive, 0)
+	assert.Equal(t, 2.0, float64(rl.staticLimiter.Limit()))
+}
+
+func TestLoadClientOptionsTransport(t *testing.T) {
+	path := writeConfig(t, "client.yaml", `
+symbol: TEST
+transport:
+  requestTimeout: 10s
+  timeout: 2m
+  proxyUrl: http://proxy:3128
+  maxConnsPerHost: 2
+  userAgent: fleet-bot/1.0
+`)
+	t.Setenv("SPACETRADERS_REQUEST_TIMEOUT", "15s")
+
+	options, err := LoadClientOptions(path)
+	require.NoError(t, err)
+
+	require.NotNil(t, options.Transport)
+	assert.Equal(t, 15*time.Second, options.Transport.RequestTimeout)
+	assert.Equal(t, 2*time.Minute, options.Transport.Timeout)
+	assert.Equal(t, "http://proxy:3128", options.Transport.ProxyURL)
+	assert.Equal(t, 2, options.Transport.MaxConnsPerHost)
+	assert.Equal(t, "fleet-bot/1.0", options.Transport.UserAgent)
+	assert.Equal(t, DefaultTransportOptions().IdleConnTimeout, options.Transport.IdleConnTimeout)
+
+	_, err = LoadClientOptions(writeConfig(t, "client.yaml", "symbol: TEST\ntransport:\n  proxyUrl: proxy\n"))
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
+
+func TestLoadClientOptionsTelemetryEnvironment(t *testing.T) {
+	// Describing telemetry does not enable it
+	t.Setenv("SPACETRADERS_SYMBOL", "TEST")
+	t.Setenv("SPACETRADERS_SERVICE_NAME", "miner")
+	t.Setenv("SPACETRADERS_ENVIRONMENT", "production")
+
+	options, err := LoadClientOptions("")
+	require.NoError(t, err)
+	assert.Nil(t, options.TelemetryOptions)
+
+	t.Setenv("SPACETRADERS_METRIC_EXPORTER", "stdout")
+	options, err = LoadClientOptions("")
+	require.NoError(t, err)
+	require.NotNil(t, options.TelemetryOptions)
+	assert.Equal(t, "miner", options.TelemetryOptions.ServiceName)
+	assert.Equal(t, "production", options.TelemetryOptions.Environment)
+}
+
+func TestLoadClientOptionsUnknownEnvironment(t *testing.T) {
+	t.Setenv("SPACETRADERS_SYMBOL", "TEST")
+	t.Setenv("SPACETRADERS_TOKEN_TEST", "token")
+	t.Setenv("SPACETRADERS_ACCOUNT_TOKEN", "account-token")
+
+	_, err := LoadClientOptions("")
+	require.NoError(t, err, "variables read by EnvTokenStore are known")
+
+	t.Setenv("SPACETRADERS_QUEUESIZE", "50")
+	_, err = LoadClientOptions("")
+	assert.ErrorContains(t, err, "SPACETRADERS_QUEUESIZE")
+}
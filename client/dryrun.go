@@ -0,0 +1,1021 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+)
+
+// dryRunState holds the game state observed from GET responses. In dry-run mode
+// mutating requests are validated against it and answered with synthetic responses
+// instead of being sent to the API.
+type dryRunState struct {
+	mu        sync.Mutex
+	agent     *models.Agent
+	ships     map[string]*models.Ship
+	contracts map[string]*models.Contract
+	systems   map[string]*models.System
+	waypoints map[string]*models.Waypoint
+	markets   map[string]*models.Market
+	shipyards map[string]*models.Shipyard
+	sites     map[string]*models.ConstructionSite
+}
+
+func newDryRunState() *dryRunState {
+	return &dryRunState{
+		ships:     make(map[string]*models.Ship),
+		contracts: make(map[string]*models.Contract),
+		systems:   make(map[string]*models.System),
+		waypoints: make(map[string]*models.Waypoint),
+		markets:   make(map[string]*models.Market),
+		shipyards: make(map[string]*models.Shipyard),
+		sites:     make(map[string]*models.ConstructionSite),
+	}
+}
+
+// IsDryRun reports whether the client simulates mutating requests instead of sending them
+func (c *Client) IsDryRun() bool {
+	return c.dryRun != nil
+}
+
+// dryRunError builds the error returned when a simulated request fails validation
+func dryRunError(code int, format string, args ...interface{}) *models.APIError {
+	return &models.APIError{
+		Code:    code,
+		Message: "dry run: " + fmt.Sprintf(format, args...),
+	}
+}
+
+// endpointSegments splits an endpoint into its path segments
+func endpointSegments(endpoint string) []string {
+	return strings.Split(strings.Trim(endpoint, "/"), "/")
+}
+
+// observe records state from the body of a successful GET response
+func (d *dryRunState) observe(endpoint string, body []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	segments := endpointSegments(endpoint)
+
+	switch {
+	case endpoint == "/my/agent":
+		var response struct {
+			Data models.Agent `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			d.agent = &response.Data
+		}
+	case endpoint == "/my/ships":
+		var response struct {
+			Data []models.Ship `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			for i := range response.Data {
+				ship := response.Data[i]
+				d.ships[ship.Symbol] = &ship
+			}
+		}
+	case len(segments) == 3 && segments[0] == "my" && segments[1] == "ships":
+		var response struct {
+			Data models.Ship `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			d.ships[response.Data.Symbol] = &response.Data
+		}
+	case len(segments) == 4 && segments[0] == "my" && segments[1] == "ships":
+		ship, ok := d.ships[segments[2]]
+		if !ok {
+			return
+		}
+		switch segments[3] {
+		case "nav":
+			var response struct {
+				Data models.ShipNav `json:"data"`
+			}
+			if json.Unmarshal(body, &response) == nil {
+				ship.Nav = response.Data
+			}
+		case "cargo":
+			var response struct {
+				Data models.Cargo `json:"data"`
+			}
+			if json.Unmarshal(body, &response) == nil {
+				ship.Cargo = response.Data
+			}
+		}
+	case endpoint == "/my/contracts":
+		var response struct {
+			Data []models.Contract `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			for i := range response.Data {
+				contract := response.Data[i]
+				d.contracts[contract.ID] = &contract
+			}
+		}
+	case len(segments) == 3 && segments[0] == "my" && segments[1] == "contracts":
+		var response struct {
+			Data models.Contract `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			d.contracts[response.Data.ID] = &response.Data
+		}
+	case endpoint == "/systems":
+		var response struct {
+			Data []models.System `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			for i := range response.Data {
+				system := response.Data[i]
+				d.systems[system.Symbol] = &system
+			}
+		}
+	case len(segments) == 2 && segments[0] == "systems":
+		var response struct {
+			Data models.System `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			d.systems[response.Data.Symbol] = &response.Data
+		}
+	case len(segments) == 3 && segments[0] == "systems" && segments[2] == "waypoints":
+		var response struct {
+			Data []models.Waypoint `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			for i := range response.Data {
+				waypoint := response.Data[i]
+				d.waypoints[waypoint.Symbol] = &waypoint
+			}
+		}
+	case len(segments) == 4 && segments[0] == "systems" && segments[2] == "waypoints":
+		var response struct {
+			Data models.Waypoint `json:"data"`
+		}
+		if json.Unmarshal(body, &response) == nil {
+			d.waypoints[response.Data.Symbol] = &response.Data
+		}
+	case len(segments) == 5 && segments[0] == "systems" && segments[2] == "waypoints":
+		switch segments[4] {
+		case "market":
+			var response struct {
+				Data models.Market `json:"data"`
+			}
+			if json.Unmarshal(body, &response) == nil {
+				d.markets[segments[3]] = &response.Data
+			}
+		case "shipyard":
+			var response struct {
+				Data models.Shipyard `json:"data"`
+			}
+			if json.Unmarshal(body, &response) == nil {
+				d.shipyards[segments[3]] = &response.Data
+			}
+		case "construction":
+			var response struct {
+				Data models.ConstructionSite `json:"data"`
+			}
+			if json.Unmarshal(body, &response) == nil {
+				d.sites[segments[3]] = &response.Data
+			}
+		}
+	}
+}
+
+// simulate validates a mutating request against the observed state and decodes a
+// synthetic response into result
+func (d *dryRunState) simulate(method, endpoint string, body interface{}, result interface{}) *models.APIError {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	segments := endpointSegments(endpoint)
+
+	var data interface{}
+	var apiErr *models.APIError
+
+	switch {
+	case endpoint == "/register":
+		apiErr = dryRunError(400, "agents cannot be registered in dry-run mode")
+	case method == "POST" && endpoint == "/my/ships":
+		data, apiErr = d.purchaseShip(body)
+	case len(segments) >= 4 && segments[0] == "my" && segments[1] == "ships":
+		data, apiErr = d.simulateShip(method, segments[2], strings.Join(segments[3:], "/"), body)
+	case len(segments) == 4 && segments[0] == "my" && segments[1] == "contracts":
+		data, apiErr = d.simulateContract(segments[2], segments[3], body)
+	case len(segments) == 6 && segments[0] == "systems" && segments[4] == "construction" && segments[5] == "supply":
+		data, apiErr = d.supplyConstruction(segments[3], body)
+	default:
+		apiErr = dryRunError(400, "no simulation available for %s %s", method, endpoint)
+	}
+
+	if apiErr != nil {
+		return apiErr
+	}
+
+	return decodeInto(map[string]interface{}{"data": data}, result)
+}
+
+// decodeInto round-trips value through JSON into result
+func decodeInto(value interface{}, result interface{}) *models.APIError {
+	if result == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return dryRunError(500, "failed to encode synthetic response: %v", err)
+	}
+	if err := json.Unmarshal(encoded, result); err != nil {
+		return dryRunError(500, "failed to decode synthetic response: %v", err)
+	}
+	return nil
+}
+
+// decodeRequest converts a request body into the given request model
+func decodeRequest(body interface{}, request interface{}) *models.APIError {
+	if body == nil {
+		return nil
+	}
+
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return dryRunError(400, "invalid request body: %v", err)
+	}
+	if err := json.Unmarshal(encoded, request); err != nil {
+		return dryRunError(400, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+func (d *dryRunState) simulateShip(method, shipSymbol, action string, body interface{}) (interface{}, *models.APIError) {
+	ship, ok := d.ships[shipSymbol]
+	if !ok {
+		return nil, dryRunError(404, "ship %s has not been fetched yet", shipSymbol)
+	}
+
+	arriveIfDue(ship)
+
+	if method == "PATCH" && action == "nav" {
+		var request models.NavUpdateRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		ship.Nav.FlightMode = request.FlightMode
+		return ship.Nav, nil
+	}
+
+	switch action {
+	case "orbit":
+		if err := requireNotInTransit(ship); err != nil {
+			return nil, err
+		}
+		ship.Nav.Status = models.NavStatusInOrbit
+		return map[string]interface{}{"nav": ship.Nav}, nil
+
+	case "dock":
+		if err := requireNotInTransit(ship); err != nil {
+			return nil, err
+		}
+		ship.Nav.Status = models.NavStatusDocked
+		return map[string]interface{}{"nav": ship.Nav}, nil
+
+	case "navigate":
+		var request models.NavigateRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		return d.navigate(ship, request.WaypointSymbol)
+
+	case "warp":
+		var request models.WarpRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		return d.warp(ship, request.WaypointSymbol)
+
+	case "jump":
+		var request models.JumpShipRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		if err := requireStatus(ship, models.NavStatusInOrbit); err != nil {
+			return nil, err
+		}
+		if err := requireCooldownExpired(ship); err != nil {
+			return nil, err
+		}
+		systemSymbol := systemFromWaypoint(request.WaypointSymbol)
+		ship.Nav.SystemSymbol = systemSymbol
+		ship.Nav.WaypointSymbol = request.WaypointSymbol
+		ship.Nav.Route.Destination = models.RouteWaypoint{Symbol: request.WaypointSymbol, SystemSymbol: systemSymbol}
+		ship.Cooldown = newCooldown(ship.Symbol, 60)
+		return map[string]interface{}{
+			"nav":         ship.Nav,
+			"cooldown":    ship.Cooldown,
+			"transaction": d.transaction(ship, "", "PURCHASE", 0, 0),
+			"agent":       d.currentAgent(),
+		}, nil
+
+	case "sell", "purchase":
+		var request models.SellCargoRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		return d.trade(ship, action, request.Symbol, request.Units)
+
+	case "refuel":
+		var request models.RefuelShipRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		return d.refuel(ship, request)
+
+	case "jettison":
+		var request models.JettisonRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		if err := removeCargo(&ship.Cargo, string(request.Symbol), request.Units); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"cargo": ship.Cargo}, nil
+
+	case "transfer":
+		var request models.TransferCargoRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		if err := removeCargo(&ship.Cargo, string(request.TradeSymbol), request.Units); err != nil {
+			return nil, err
+		}
+		if target, ok := d.ships[request.ShipSymbol]; ok {
+			addCargo(&target.Cargo, string(request.TradeSymbol), request.Units)
+		}
+		return map[string]interface{}{"cargo": ship.Cargo}, nil
+
+	case "extract", "extract/survey", "siphon":
+		if err := requireStatus(ship, models.NavStatusInOrbit); err != nil {
+			return nil, err
+		}
+		if err := requireCooldownExpired(ship); err != nil {
+			return nil, err
+		}
+		ship.Cooldown = newCooldown(ship.Symbol, 70)
+		// Yields cannot be predicted, so simulated extractions produce nothing
+		return map[string]interface{}{
+			"cooldown":   ship.Cooldown,
+			"extraction": models.Extraction{ShipSymbol: ship.Symbol},
+			"cargo":      ship.Cargo,
+			"events":     []models.Event{},
+		}, nil
+
+	case "survey":
+		if err := requireStatus(ship, models.NavStatusInOrbit); err != nil {
+			return nil, err
+		}
+		if err := requireCooldownExpired(ship); err != nil {
+			return nil, err
+		}
+		ship.Cooldown = newCooldown(ship.Symbol, 60)
+		return map[string]interface{}{"cooldown": ship.Cooldown, "surveys": []models.Survey{}}, nil
+
+	case "refine":
+		var request models.RefineRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		if err := requireCooldownExpired(ship); err != nil {
+			return nil, err
+		}
+		ship.Cooldown = newCooldown(ship.Symbol, 60)
+		return map[string]interface{}{
+			"cargo":    ship.Cargo,
+			"cooldown": ship.Cooldown,
+			"produced": models.Produced{TradeSymbol: models.GoodSymbol(request.Produce)},
+			"consumed": models.Consumed{},
+		}, nil
+
+	case "chart":
+		waypoint, ok := d.waypoints[ship.Nav.WaypointSymbol]
+		if !ok {
+			waypoint = &models.Waypoint{Symbol: ship.Nav.WaypointSymbol}
+		}
+		chart := models.Chart{
+			WaypointSymbol: ship.Nav.WaypointSymbol,
+			SubmittedBy:    d.currentAgent().Symbol,
+			SubmittedOn:    time.Now().UTC().Format(time.RFC3339),
+		}
+		return map[string]interface{}{"chart": chart, "waypoint": waypoint}, nil
+
+	case "scan/systems", "scan/waypoints", "scan/ships":
+		if err := requireCooldownExpired(ship); err != nil {
+			return nil, err
+		}
+		ship.Cooldown = newCooldown(ship.Symbol, 60)
+		return map[string]interface{}{
+			"cooldown":  ship.Cooldown,
+			"systems":   []models.System{},
+			"waypoints": []models.Waypoint{},
+			"ships":     []models.Ship{},
+		}, nil
+
+	case "repair":
+		if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+			return nil, err
+		}
+		ship.Frame.Condition = 1
+		ship.Reactor.Condition = 1
+		ship.Engine.Condition = 1
+		return map[string]interface{}{
+			"agent":       d.currentAgent(),
+			"ship":        ship,
+			"transaction": d.transaction(ship, "", "REPAIR", 0, 0),
+		}, nil
+
+	case "scrap":
+		if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+			return nil, err
+		}
+		delete(d.ships, ship.Symbol)
+		return map[string]interface{}{
+			"agent":       d.currentAgent(),
+			"transaction": d.transaction(ship, "", "SCRAP", 0, 0),
+		}, nil
+
+	case "negotiate/contract":
+		if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"contract": d.negotiateContract(ship)}, nil
+
+	case "mounts/install", "mounts/remove":
+		var request models.InstallMountRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		return d.changeMount(ship, action == "mounts/install", string(request.Symbol))
+	}
+
+	return nil, dryRunError(400, "no simulation available for %s /my/ships/%s/%s", method, shipSymbol, action)
+}
+
+func (d *dryRunState) navigate(ship *models.Ship, destination string) (interface{}, *models.APIError) {
+	if err := requireStatus(ship, models.NavStatusInOrbit); err != nil {
+		return nil, err
+	}
+	if destination == ship.Nav.WaypointSymbol {
+		return nil, dryRunError(400, "ship %s is already at %s", ship.Symbol, destination)
+	}
+
+	// Fuel and travel time depend on the distance, so both waypoints must have been fetched
+	origin, ok := d.waypoints[ship.Nav.WaypointSymbol]
+	if !ok {
+		return nil, dryRunError(400, "waypoint %s has not been fetched yet", ship.Nav.WaypointSymbol)
+	}
+	target, ok := d.waypoints[destination]
+	if !ok {
+		return nil, dryRunError(400, "waypoint %s has not been fetched yet", destination)
+	}
+	if systemFromWaypoint(target.Symbol) != ship.Nav.SystemSymbol {
+		return nil, dryRunError(400, "waypoint %s is not in the ship's system %s", destination, ship.Nav.SystemSymbol)
+	}
+
+	distance := math.Round(math.Sqrt(math.Pow(float64(origin.X-target.X), 2) + math.Pow(float64(origin.Y-target.Y), 2)))
+	return d.depart(ship, destination, distance, dryRunTravelTime(distance, ship.Nav.FlightMode, ship.Engine.Speed))
+}
+
+// warp moves a ship to a waypoint in another system. Fuel and travel time depend on the
+// distance between the systems, so both must have been fetched; the destination waypoint
+// itself need not have been.
+func (d *dryRunState) warp(ship *models.Ship, destination string) (interface{}, *models.APIError) {
+	if err := requireStatus(ship, models.NavStatusInOrbit); err != nil {
+		return nil, err
+	}
+
+	systemSymbol := systemFromWaypoint(destination)
+	if systemSymbol == ship.Nav.SystemSymbol {
+		return nil, dryRunError(400, "waypoint %s is in the ship's system %s, navigate instead", destination, systemSymbol)
+	}
+	origin, ok := d.systems[ship.Nav.SystemSymbol]
+	if !ok {
+		return nil, dryRunError(400, "system %s has not been fetched yet", ship.Nav.SystemSymbol)
+	}
+	target, ok := d.systems[systemSymbol]
+	if !ok {
+		return nil, dryRunError(400, "system %s has not been fetched yet", systemSymbol)
+	}
+
+	distance := math.Round(math.Sqrt(math.Pow(float64(origin.X-target.X), 2) + math.Pow(float64(origin.Y-target.Y), 2)))
+	return d.depart(ship, destination, distance, dryRunWarpTime(distance, ship.Nav.FlightMode, ship.Engine.Speed))
+}
+
+// depart spends the fuel for a flight of the given distance and puts the ship in transit
+// to destination, arriving after travelTime seconds
+func (d *dryRunState) depart(ship *models.Ship, destination string, distance float64, travelTime int) (interface{}, *models.APIError) {
+	fuel := dryRunFuelRequired(distance, ship.Nav.FlightMode)
+	if ship.Fuel.Capacity > 0 {
+		if ship.Fuel.Current < fuel {
+			return nil, dryRunError(400, "ship %s needs %d fuel to reach %s but has %d", ship.Symbol, fuel, destination, ship.Fuel.Current)
+		}
+		ship.Fuel.Current -= fuel
+		ship.Fuel.Consumed = models.FuelConsumed{Amount: fuel, Timestamp: time.Now().UTC().Format(time.RFC3339)}
+	}
+
+	now := time.Now().UTC()
+	arrival := now.Add(time.Duration(travelTime) * time.Second)
+
+	ship.Nav.Route = models.ShipNavRoute{
+		Origin:        routeWaypoint(ship.Nav.WaypointSymbol, ship.Nav.SystemSymbol, d.waypoints[ship.Nav.WaypointSymbol]),
+		Destination:   routeWaypoint(destination, systemFromWaypoint(destination), d.waypoints[destination]),
+		DepartureTime: now.Format(time.RFC3339),
+		Arrival:       arrival.Format(time.RFC3339),
+	}
+	ship.Nav.Status = models.NavStatusInTransit
+	ship.Nav.WaypointSymbol = destination
+	ship.Nav.SystemSymbol = systemFromWaypoint(destination)
+
+	return map[string]interface{}{"fuel": ship.Fuel, "nav": ship.Nav, "events": []models.Event{}}, nil
+}
+
+func (d *dryRunState) trade(ship *models.Ship, action string, goodSymbol models.GoodSymbol, units int) (interface{}, *models.APIError) {
+	if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+		return nil, err
+	}
+	if units <= 0 {
+		return nil, dryRunError(400, "units must be greater than zero")
+	}
+
+	market, ok := d.markets[ship.Nav.WaypointSymbol]
+	if !ok {
+		return nil, dryRunError(400, "market at %s has not been fetched yet", ship.Nav.WaypointSymbol)
+	}
+
+	var tradeGood *models.MarketTradeGoods
+	for i := range market.TradeGoods {
+		if market.TradeGoods[i].Symbol == goodSymbol {
+			tradeGood = &market.TradeGoods[i]
+			break
+		}
+	}
+	if tradeGood == nil {
+		return nil, dryRunError(400, "market at %s does not trade %s", ship.Nav.WaypointSymbol, goodSymbol)
+	}
+
+	var transaction models.Transaction
+	if action == "sell" {
+		if err := removeCargo(&ship.Cargo, string(goodSymbol), units); err != nil {
+			return nil, err
+		}
+		transaction = d.transaction(ship, string(goodSymbol), "SELL", units, tradeGood.SellPrice)
+		d.adjustCredits(int64(transaction.TotalPrice))
+	} else {
+		if available := ship.Cargo.Capacity - ship.Cargo.Units; available < units {
+			return nil, dryRunError(400, "ship %s has %d units of cargo space, %d requested", ship.Symbol, available, units)
+		}
+		transaction = d.transaction(ship, string(goodSymbol), "PURCHASE", units, tradeGood.PurchasePrice)
+		if err := d.spendCredits(int64(transaction.TotalPrice)); err != nil {
+			return nil, err
+		}
+		addCargo(&ship.Cargo, string(goodSymbol), units)
+	}
+
+	return map[string]interface{}{
+		"agent":       d.currentAgent(),
+		"cargo":       ship.Cargo,
+		"transaction": transaction,
+	}, nil
+}
+
+func (d *dryRunState) refuel(ship *models.Ship, request models.RefuelShipRequest) (interface{}, *models.APIError) {
+	if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+		return nil, err
+	}
+
+	units := request.Units
+	if missing := ship.Fuel.Capacity - ship.Fuel.Current; units <= 0 || units > missing {
+		units = missing
+	}
+
+	// Each unit of the FUEL trade good holds 100 units of ship fuel
+	goodUnits := (units + 99) / 100
+
+	var transaction models.Transaction
+	if request.FromCargo {
+		if err := removeCargo(&ship.Cargo, "FUEL", goodUnits); err != nil {
+			return nil, err
+		}
+		transaction = d.transaction(ship, "FUEL", "PURCHASE", goodUnits, 0)
+	} else {
+		price := 0
+		if market, ok := d.markets[ship.Nav.WaypointSymbol]; ok {
+			for _, good := range market.TradeGoods {
+				if good.Symbol == "FUEL" {
+					price = good.PurchasePrice
+				}
+			}
+		}
+		transaction = d.transaction(ship, "FUEL", "PURCHASE", goodUnits, price)
+		if err := d.spendCredits(int64(transaction.TotalPrice)); err != nil {
+			return nil, err
+		}
+	}
+
+	ship.Fuel.Current += units
+
+	return map[string]interface{}{
+		"agent":       d.currentAgent(),
+		"fuel":        ship.Fuel,
+		"transaction": transaction,
+	}, nil
+}
+
+func (d *dryRunState) changeMount(ship *models.Ship, install bool, mountSymbol string) (interface{}, *models.APIError) {
+	if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+		return nil, err
+	}
+
+	if install {
+		if err := removeCargo(&ship.Cargo, mountSymbol, 1); err != nil {
+			return nil, err
+		}
+		ship.Mounts = append(ship.Mounts, models.ShipMount{Symbol: mountSymbol})
+	} else {
+		index := -1
+		for i, mount := range ship.Mounts {
+			if mount.Symbol == mountSymbol {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, dryRunError(400, "ship %s has no %s mount installed", ship.Symbol, mountSymbol)
+		}
+		ship.Mounts = append(ship.Mounts[:index], ship.Mounts[index+1:]...)
+		addCargo(&ship.Cargo, mountSymbol, 1)
+	}
+
+	return map[string]interface{}{
+		"agent":       d.currentAgent(),
+		"mounts":      ship.Mounts,
+		"cargo":       ship.Cargo,
+		"transaction": d.transaction(ship, mountSymbol, "PURCHASE", 1, 0),
+	}, nil
+}
+
+func (d *dryRunState) purchaseShip(body interface{}) (interface{}, *models.APIError) {
+	var request models.PurchaseShipRequest
+	if err := decodeRequest(body, &request); err != nil {
+		return nil, err
+	}
+
+	shipyard, ok := d.shipyards[request.WaypointSymbol]
+	if !ok {
+		return nil, dryRunError(400, "shipyard at %s has not been fetched yet", request.WaypointSymbol)
+	}
+
+	var listing *models.ShipyardShip
+	for i := range shipyard.Ships {
+		if shipyard.Ships[i].Type == request.ShipType {
+			listing = &shipyard.Ships[i]
+			break
+		}
+	}
+	if listing == nil {
+		return nil, dryRunError(400, "shipyard at %s does not sell %s", request.WaypointSymbol, request.ShipType)
+	}
+
+	if err := d.spendCredits(int64(listing.PurchasePrice)); err != nil {
+		return nil, err
+	}
+
+	agentSymbol := ""
+	if d.agent != nil {
+		agentSymbol = d.agent.Symbol
+		d.agent.ShipCount++
+	}
+
+	cargoCapacity := 0
+	for _, module := range listing.Modules {
+		if strings.Contains(module.Symbol, "CARGO") {
+			cargoCapacity += module.Capacity
+		}
+	}
+
+	systemSymbol := systemFromWaypoint(request.WaypointSymbol)
+	ship := &models.Ship{
+		Symbol: fmt.Sprintf("%s-DRYRUN-%d", agentSymbol, len(d.ships)+1),
+		Nav: models.ShipNav{
+			SystemSymbol:   systemSymbol,
+			WaypointSymbol: request.WaypointSymbol,
+			Status:         models.NavStatusDocked,
+			FlightMode:     models.FlightModeCruise,
+		},
+		Crew:    listing.Crew,
+		Frame:   listing.Frame,
+		Reactor: listing.Reactor,
+		Engine:  listing.Engine,
+		Modules: listing.Modules,
+		Mounts:  listing.Mounts,
+		Cargo:   models.Cargo{Capacity: cargoCapacity, Inventory: []models.Inventory{}},
+		Fuel:    models.FuelDetails{Current: listing.Frame.FuelCapacity, Capacity: listing.Frame.FuelCapacity},
+	}
+	d.ships[ship.Symbol] = ship
+
+	transaction := d.transaction(ship, "", "PURCHASE", 1, listing.PurchasePrice)
+	transaction.ShipType = string(request.ShipType)
+
+	return map[string]interface{}{
+		"agent":       d.currentAgent(),
+		"ship":        ship,
+		"transaction": transaction,
+	}, nil
+}
+
+// negotiateContract offers a synthetic contract at the ship's waypoint. Terms cannot be
+// predicted, so it has no deliveries or payment; it can be accepted and fulfilled like any
+// fetched contract.
+func (d *dryRunState) negotiateContract(ship *models.Ship) *models.Contract {
+	now := time.Now().UTC()
+	contract := &models.Contract{
+		ID:               fmt.Sprintf("dry-run-%s-%d", ship.Symbol, now.UnixNano()),
+		FactionSymbol:    d.currentAgent().StartingFaction,
+		Type:             "PROCUREMENT",
+		Terms:            models.ContractTerms{Deadline: now.Add(7 * 24 * time.Hour).Format(time.RFC3339), Deliver: []models.ContractDeliver{}},
+		Expiration:       now.Add(24 * time.Hour).Format(time.RFC3339),
+		DeadlineToAccept: now.Add(24 * time.Hour).Format(time.RFC3339),
+	}
+	d.contracts[contract.ID] = contract
+	return contract
+}
+
+func (d *dryRunState) simulateContract(contractID, action string, body interface{}) (interface{}, *models.APIError) {
+	contract, ok := d.contracts[contractID]
+	if !ok {
+		return nil, dryRunError(404, "contract %s has not been fetched yet", contractID)
+	}
+
+	switch action {
+	case "accept":
+		if contract.Accepted {
+			return nil, dryRunError(400, "contract %s has already been accepted", contractID)
+		}
+		contract.Accepted = true
+		d.adjustCredits(int64(contract.Terms.Payment.OnAccepted))
+		return map[string]interface{}{"agent": d.currentAgent(), "contract": contract}, nil
+
+	case "deliver":
+		var request models.DeliverContractCargoRequest
+		if err := decodeRequest(body, &request); err != nil {
+			return nil, err
+		}
+		ship, ok := d.ships[request.ShipSymbol]
+		if !ok {
+			return nil, dryRunError(404, "ship %s has not been fetched yet", request.ShipSymbol)
+		}
+		if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+			return nil, err
+		}
+
+		var term *models.ContractDeliver
+		for i := range contract.Terms.Deliver {
+			if contract.Terms.Deliver[i].TradeSymbol == string(request.TradeSymbol) {
+				term = &contract.Terms.Deliver[i]
+				break
+			}
+		}
+		if term == nil {
+			return nil, dryRunError(400, "contract %s does not require %s", contractID, request.TradeSymbol)
+		}
+		if remaining := term.UnitsRequired - term.UnitsFulfilled; request.Units > remaining {
+			return nil, dryRunError(400, "contract %s needs %d more units of %s, %d delivered", contractID, remaining, request.TradeSymbol, request.Units)
+		}
+		if err := removeCargo(&ship.Cargo, string(request.TradeSymbol), request.Units); err != nil {
+			return nil, err
+		}
+		term.UnitsFulfilled += request.Units
+		return map[string]interface{}{"contract": contract, "cargo": ship.Cargo}, nil
+
+	case "fulfill":
+		for _, term := range contract.Terms.Deliver {
+			if term.UnitsFulfilled < term.UnitsRequired {
+				return nil, dryRunError(400, "contract %s still needs %d units of %s", contractID, term.UnitsRequired-term.UnitsFulfilled, term.TradeSymbol)
+			}
+		}
+		contract.Fulfilled = true
+		d.adjustCredits(int64(contract.Terms.Payment.OnFulfilled))
+		return map[string]interface{}{"agent": d.currentAgent(), "contract": contract}, nil
+	}
+
+	return nil, dryRunError(400, "no simulation available for POST /my/contracts/%s/%s", contractID, action)
+}
+
+func (d *dryRunState) supplyConstruction(waypointSymbol string, body interface{}) (interface{}, *models.APIError) {
+	var request models.SupplyConstructionSiteRequest
+	if err := decodeRequest(body, &request); err != nil {
+		return nil, err
+	}
+
+	ship, ok := d.ships[request.ShipSymbol]
+	if !ok {
+		return nil, dryRunError(404, "ship %s has not been fetched yet", request.ShipSymbol)
+	}
+	if err := requireStatus(ship, models.NavStatusDocked); err != nil {
+		return nil, err
+	}
+	if err := removeCargo(&ship.Cargo, string(request.TradeSymbol), request.Units); err != nil {
+		return nil, err
+	}
+
+	site, ok := d.sites[waypointSymbol]
+	if !ok {
+		site = &models.ConstructionSite{Symbol: waypointSymbol}
+	}
+
+	return map[string]interface{}{"construction": site, "cargo": ship.Cargo}, nil
+}
+
+// currentAgent returns the observed agent, or an empty agent if none has been fetched
+func (d *dryRunState) currentAgent() models.Agent {
+	if d.agent == nil {
+		return models.Agent{}
+	}
+	return *d.agent
+}
+
+func (d *dryRunState) adjustCredits(amount int64) {
+	if d.agent != nil {
+		d.agent.Credits += amount
+	}
+}
+
+func (d *dryRunState) spendCredits(amount int64) *models.APIError {
+	if d.agent == nil {
+		return nil
+	}
+	if d.agent.Credits < amount {
+		return dryRunError(400, "agent has %d credits, %d required", d.agent.Credits, amount)
+	}
+	d.agent.Credits -= amount
+	return nil
+}
+
+func (d *dryRunState) transaction(ship *models.Ship, tradeSymbol, transactionType string, units, pricePerUnit int) models.Transaction {
+	transaction := models.Transaction{
+		WaypointSymbol: ship.Nav.WaypointSymbol,
+		ShipSymbol:     ship.Symbol,
+		TradeSymbol:    tradeSymbol,
+		Type:           transactionType,
+		Units:          units,
+		PricePerUnit:   pricePerUnit,
+		TotalPrice:     units * pricePerUnit,
+		Price:          units * pricePerUnit,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+	}
+	if d.agent != nil {
+		transaction.AgentSymbol = d.agent.Symbol
+	}
+	return transaction
+}
+
+// arriveIfDue moves a ship whose arrival time has passed into orbit at its destination
+func arriveIfDue(ship *models.Ship) {
+	if ship.Nav.Status != models.NavStatusInTransit {
+		return
+	}
+	arrival, err := time.Parse(time.RFC3339, ship.Nav.Route.Arrival)
+	if err == nil && time.Now().After(arrival) {
+		ship.Nav.Status = models.NavStatusInOrbit
+	}
+}
+
+func requireNotInTransit(ship *models.Ship) *models.APIError {
+	if ship.Nav.Status == models.NavStatusInTransit {
+		return dryRunError(400, "ship %s is in transit until %s", ship.Symbol, ship.Nav.Route.Arrival)
+	}
+	return nil
+}
+
+func requireStatus(ship *models.Ship, status models.NavStatus) *models.APIError {
+	if err := requireNotInTransit(ship); err != nil {
+		return err
+	}
+	if ship.Nav.Status != status {
+		return dryRunError(400, "ship %s must be %s but is %s", ship.Symbol, status, ship.Nav.Status)
+	}
+	return nil
+}
+
+func requireCooldownExpired(ship *models.Ship) *models.APIError {
+	expiration, err := time.Parse(time.RFC3339, ship.Cooldown.Expiration)
+	if err == nil && ship.Cooldown.RemainingSeconds > 0 && time.Now().Before(expiration) {
+		return dryRunError(400, "ship %s is on cooldown until %s", ship.Symbol, ship.Cooldown.Expiration)
+	}
+	return nil
+}
+
+func newCooldown(shipSymbol string, seconds int) models.ShipCooldown {
+	return models.ShipCooldown{
+		ShipSymbol:       shipSymbol,
+		TotalSeconds:     seconds,
+		RemainingSeconds: seconds,
+		Expiration:       time.Now().UTC().Add(time.Duration(seconds) * time.Second).Format(time.RFC3339),
+	}
+}
+
+func removeCargo(cargo *models.Cargo, symbol string, units int) *models.APIError {
+	if units <= 0 {
+		return dryRunError(400, "units must be greater than zero")
+	}
+	for i, item := range cargo.Inventory {
+		if item.Symbol != symbol {
+			continue
+		}
+		if item.Units < units {
+			break
+		}
+		cargo.Inventory[i].Units -= units
+		if cargo.Inventory[i].Units == 0 {
+			cargo.Inventory = append(cargo.Inventory[:i], cargo.Inventory[i+1:]...)
+		}
+		cargo.Units -= units
+		return nil
+	}
+	return dryRunError(400, "insufficient %s in cargo for %d units", symbol, units)
+}
+
+func addCargo(cargo *models.Cargo, symbol string, units int) {
+	cargo.Units += units
+	for i, item := range cargo.Inventory {
+		if item.Symbol == symbol {
+			cargo.Inventory[i].Units += units
+			return
+		}
+	}
+	cargo.Inventory = append(cargo.Inventory, models.Inventory{Symbol: symbol, Units: units})
+}
+
+func routeWaypoint(symbol, systemSymbol string, waypoint *models.Waypoint) models.RouteWaypoint {
+	route := models.RouteWaypoint{Symbol: symbol, SystemSymbol: systemSymbol}
+	if waypoint != nil {
+		route.Type = string(waypoint.Type)
+		route.X = waypoint.X
+		route.Y = waypoint.Y
+	}
+	return route
+}
+
+// systemFromWaypoint derives a system symbol such as X1-AB12 from a waypoint symbol such as X1-AB12-C34
+func systemFromWaypoint(waypointSymbol string) string {
+	if i := strings.LastIndex(waypointSymbol, "-"); i > 0 {
+		return waypointSymbol[:i]
+	}
+	return waypointSymbol
+}
+
+// dryRunFuelRequired mirrors the game's fuel formula for a flight of the given distance
+func dryRunFuelRequired(distance float64, flightMode models.FlightMode) int {
+	switch flightMode {
+	case models.FlightModeDrift:
+		return 1
+	case models.FlightModeBurn:
+		return int(math.Max(2, 2*math.Round(distance)))
+	default:
+		return int(math.Round(distance))
+	}
+}
+
+// dryRunWarpTime mirrors the game's warp time formula, in seconds. Warps are slower than
+// navigating the same distance within a system.
+func dryRunWarpTime(distance float64, flightMode models.FlightMode, speed int) int {
+	multiplier := 50.0
+	switch flightMode {
+	case models.FlightModeDrift:
+		multiplier = 300
+	case models.FlightModeBurn:
+		multiplier = 25
+	}
+	if speed <= 0 {
+		speed = 1
+	}
+	return int(math.Round(math.Round(math.Max(1, distance))*(multiplier/float64(speed)) + 15))
+}
+
+// dryRunTravelTime mirrors the game's travel time formula, in seconds
+func dryRunTravelTime(distance float64, flightMode models.FlightMode, speed int) int {
+	multiplier := 25.0
+	switch flightMode {
+	case models.FlightModeDrift:
+		multiplier = 250
+	case models.FlightModeBurn:
+		multiplier = 12.5
+	}
+	if speed <= 0 {
+		speed = 1
+	}
+	return int(math.Round(math.Round(math.Max(1, distance))*(multiplier/float64(speed)) + 15))
+}
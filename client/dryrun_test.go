@@ -0,0 +1,144 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dryRunShipBody = `{"data":{"symbol":"TEST-1","nav":{"systemSymbol":"X1-AB12","waypointSymbol":"X1-AB12-A1","status":"IN_ORBIT","flightMode":"CRUISE"},
+"cargo":{"capacity":40,"units":10,"inventory":[{"symbol":"IRON_ORE","units":10}]},"fuel":{"current":100,"capacity":400}}}`
+
+func TestDryRun_DockAndSell(t *testing.T) {
+	state := newDryRunState()
+	state.observe("/my/agent", []byte(`{"data":{"symbol":"TEST","credits":1000}}`))
+	state.observe("/my/ships/TEST-1", []byte(dryRunShipBody))
+	state.observe("/systems/X1-AB12/waypoints/X1-AB12-A1/market",
+		[]byte(`{"data":{"symbol":"X1-AB12-A1","tradeGoods":[{"symbol":"IRON_ORE","sellPrice":12,"purchasePrice":15}]}}`))
+
+	// Selling while in orbit is rejected
+	var sellResponse models.SellCargoResponse
+	err := state.simulate("POST", "/my/ships/TEST-1/sell", models.SellCargoRequest{Symbol: "IRON_ORE", Units: 5}, &sellResponse)
+	assert.NotNil(t, err)
+	assert.Equal(t, 400, err.Code)
+
+	var dockResponse struct {
+		Data struct {
+			Nav models.ShipNav `json:"nav"`
+		} `json:"data"`
+	}
+	assert.Nil(t, state.simulate("POST", "/my/ships/TEST-1/dock", nil, &dockResponse))
+	assert.Equal(t, models.NavStatusDocked, dockResponse.Data.Nav.Status)
+
+	assert.Nil(t, state.simulate("POST", "/my/ships/TEST-1/sell", models.SellCargoRequest{Symbol: "IRON_ORE", Units: 5}, &sellResponse))
+	assert.Equal(t, 5, sellResponse.Data.Cargo.Units)
+	assert.Equal(t, 60, sellResponse.Data.Transaction.TotalPrice)
+	assert.Equal(t, int64(1060), sellResponse.Data.Agent.Credits)
+}
+
+func TestDryRun_Navigate(t *testing.T) {
+	state := newDryRunState()
+	state.observe("/my/ships/TEST-1", []byte(dryRunShipBody))
+	state.observe("/systems/X1-AB12/waypoints",
+		[]byte(`{"data":[{"symbol":"X1-AB12-A1","x":0,"y":0},{"symbol":"X1-AB12-B2","x":30,"y":40},{"symbol":"X1-AB12-C3","x":300,"y":400}]}`))
+
+	var response models.NavigateResponse
+	assert.Nil(t, state.simulate("POST", "/my/ships/TEST-1/navigate", models.NavigateRequest{WaypointSymbol: "X1-AB12-B2"}, &response))
+	assert.Equal(t, models.NavStatusInTransit, response.Data.Nav.Status)
+	assert.Equal(t, "X1-AB12-B2", response.Data.Nav.Route.Destination.Symbol)
+	assert.Equal(t, 50, response.Data.Fuel.Current)
+
+	// The ship is still in transit, so a second navigation is rejected
+	err := state.simulate("POST", "/my/ships/TEST-1/navigate", models.NavigateRequest{WaypointSymbol: "X1-AB12-C3"}, &response)
+	assert.NotNil(t, err)
+}
+
+func TestDryRun_NavigateUnknownWaypoint(t *testing.T) {
+	state := newDryRunState()
+	state.observe("/my/ships/TEST-1", []byte(dryRunShipBody))
+
+	// Without the waypoints the distance, fuel and travel time are unknown
+	var response models.NavigateResponse
+	err := state.simulate("POST", "/my/ships/TEST-1/navigate", models.NavigateRequest{WaypointSymbol: "X1-AB12-B2"}, &response)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "X1-AB12-A1 has not been fetched")
+
+	state.observe("/systems/X1-AB12/waypoints/X1-AB12-A1", []byte(`{"data":{"symbol":"X1-AB12-A1","x":0,"y":0}}`))
+	err = state.simulate("POST", "/my/ships/TEST-1/navigate", models.NavigateRequest{WaypointSymbol: "X1-AB12-B2"}, &response)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "X1-AB12-B2 has not been fetched")
+
+	// Waypoints in other systems need a jump or warp
+	state.observe("/systems/X1-CD34/waypoints/X1-CD34-A1", []byte(`{"data":{"symbol":"X1-CD34-A1","x":10,"y":10}}`))
+	err = state.simulate("POST", "/my/ships/TEST-1/navigate", models.NavigateRequest{WaypointSymbol: "X1-CD34-A1"}, &response)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "not in the ship's system")
+
+	ship := state.ships["TEST-1"]
+	assert.Equal(t, models.NavStatusInOrbit, ship.Nav.Status)
+	assert.Equal(t, 100, ship.Fuel.Current)
+}
+
+func TestDryRun_UnknownShip(t *testing.T) {
+	state := newDryRunState()
+	err := state.simulate("POST", "/my/ships/MISSING-1/orbit", nil, nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, 404, err.Code)
+}
+
+func TestDryRun_Warp(t *testing.T) {
+	state := newDryRunState()
+	state.observe("/my/ships/TEST-1", []byte(dryRunShipBody))
+
+	var response models.WarpResponse
+	err := state.simulate("POST", "/my/ships/TEST-1/warp", models.WarpRequest{WaypointSymbol: "X1-CD34-A1"}, &response)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "system X1-AB12 has not been fetched")
+
+	state.observe("/systems", []byte(`{"data":[{"symbol":"X1-AB12","x":0,"y":0},{"symbol":"X1-CD34","x":60,"y":80}]}`))
+
+	// Waypoints in the ship's own system are navigated to
+	err = state.simulate("POST", "/my/ships/TEST-1/warp", models.WarpRequest{WaypointSymbol: "X1-AB12-B2"}, &response)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "navigate instead")
+
+	require.Nil(t, state.simulate("POST", "/my/ships/TEST-1/warp", models.WarpRequest{WaypointSymbol: "X1-CD34-A1"}, &response))
+	assert.Equal(t, models.NavStatusInTransit, response.Data.Nav.Status)
+	assert.Equal(t, "X1-CD34", response.Data.Nav.SystemSymbol)
+	assert.Equal(t, "X1-CD34-A1", response.Data.Nav.Route.Destination.Symbol)
+	assert.Equal(t, 0, response.Data.Fuel.Current)
+}
+
+func TestDryRun_JumpOnCooldown(t *testing.T) {
+	state := newDryRunState()
+	state.observe("/my/ships/TEST-1", []byte(dryRunShipBody))
+
+	jump := models.JumpShipRequest{WaypointSymbol: "X1-CD34-A1"}
+	require.Nil(t, state.simulate("POST", "/my/ships/TEST-1/jump", jump, nil))
+
+	jump.WaypointSymbol = "X1-AB12-A1"
+	err := state.simulate("POST", "/my/ships/TEST-1/jump", jump, nil)
+	require.NotNil(t, err)
+	assert.Contains(t, err.Message, "on cooldown")
+}
+
+func TestDryRun_NegotiateContract(t *testing.T) {
+	state := newDryRunState()
+	state.observe("/my/ships/TEST-1", []byte(dryRunShipBody))
+
+	var response models.NegotiateContractResponse
+	err := state.simulate("POST", "/my/ships/TEST-1/negotiate/contract", nil, &response)
+	require.NotNil(t, err, "contracts are negotiated while docked")
+
+	require.Nil(t, state.simulate("POST", "/my/ships/TEST-1/dock", nil, nil))
+	require.Nil(t, state.simulate("POST", "/my/ships/TEST-1/negotiate/contract", nil, &response))
+	contract := response.Data.Contract
+	require.NotEmpty(t, contract.ID)
+	assert.False(t, contract.Accepted)
+
+	// The negotiated contract can be accepted like a fetched one
+	require.Nil(t, state.simulate("POST", "/my/contracts/"+contract.ID+"/accept", nil, nil))
+	assert.True(t, state.contracts[contract.ID].Accepted)
+}
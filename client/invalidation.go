@@ -0,0 +1,183 @@
+package client
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Mutation is a successful mutating request, passed to invalidation rules
+type Mutation struct {
+	Method   string
+	Endpoint string
+	// Body is the request body, nil if none was sent
+	Body interface{}
+	// Response is the JSON response body
+	Response []byte
+}
+
+// Invalidation returns the CacheClient keys made stale by a mutation. A key ending in "*"
+// removes every key starting with the text before it.
+type Invalidation func(mutation Mutation) []string
+
+// invalidationRule runs an invalidation after requests with method to endpoints matching pattern
+type invalidationRule struct {
+	method       string
+	pattern      string
+	invalidation Invalidation
+}
+
+// ResponseKeys returns the CacheClient keys of the cached GET responses for endpoint,
+// with and without query parameters
+func ResponseKeys(endpoint string) []string {
+	key := responseCacheKey(endpoint, nil)
+	return []string{key, key + "?*"}
+}
+
+// RegisterInvalidation runs invalidation after every successful request with method to an
+// endpoint matching pattern, using the same syntax as CacheRule, and removes the keys it
+// returns from CacheClient and ClientOptions.CacheDir. Rules for the game's own mutations
+// are registered by default; this adds rules for derived data kept in CacheClient.
+func (c *Client) RegisterInvalidation(method, pattern string, invalidation Invalidation) {
+	c.invalidationMu.Lock()
+	defer c.invalidationMu.Unlock()
+
+	c.invalidations = append(c.invalidations, invalidationRule{
+		method:       method,
+		pattern:      pattern,
+		invalidation: invalidation,
+	})
+}
+
+// defaultInvalidations returns the rules removing cached data made stale by the game's mutations
+func defaultInvalidations() []invalidationRule {
+	// Trades change the market's prices and supply, and the ship's cargo and agent's credits
+	trade := func(m Mutation) []string {
+		var response struct {
+			Data struct {
+				Transaction struct {
+					WaypointSymbol string `json:"waypointSymbol"`
+				} `json:"transaction"`
+			} `json:"data"`
+		}
+		decodeMutation(m.Response, &response)
+
+		keys := agentShipKeys(endpointSegments(m.Endpoint)[2])
+		if waypoint := response.Data.Transaction.WaypointSymbol; waypoint != "" {
+			keys = append(keys, ResponseKeys(waypointEndpoint(waypoint)+"/market")...)
+		}
+		return keys
+	}
+
+	return []invalidationRule{
+		{method: "POST", pattern: "/my/ships/*/purchase", invalidation: trade},
+		{method: "POST", pattern: "/my/ships/*/sell", invalidation: trade},
+		{method: "POST", pattern: "/my/ships", invalidation: func(m Mutation) []string {
+			var response struct {
+				Data struct {
+					Transaction struct {
+						WaypointSymbol string `json:"waypointSymbol"`
+					} `json:"transaction"`
+				} `json:"data"`
+			}
+			decodeMutation(m.Response, &response)
+
+			keys := append(ResponseKeys("/my/agent"), ResponseKeys("/my/ships")...)
+			if waypoint := response.Data.Transaction.WaypointSymbol; waypoint != "" {
+				keys = append(keys, ResponseKeys(waypointEndpoint(waypoint)+"/shipyard")...)
+			}
+			return keys
+		}},
+		// Charting adds the waypoint's traits, so the waypoint and the system's waypoint lists change
+		{method: "POST", pattern: "/my/ships/*/chart", invalidation: func(m Mutation) []string {
+			var response struct {
+				Data struct {
+					Waypoint struct {
+						Symbol string `json:"symbol"`
+					} `json:"waypoint"`
+				} `json:"data"`
+			}
+			decodeMutation(m.Response, &response)
+
+			waypoint := response.Data.Waypoint.Symbol
+			if waypoint == "" {
+				return nil
+			}
+			keys := ResponseKeys(waypointEndpoint(waypoint))
+			return append(keys, ResponseKeys("/systems/"+systemFromWaypoint(waypoint)+"/waypoints")...)
+		}},
+		// Supplying changes the construction site, and completing it changes the waypoint
+		{method: "POST", pattern: "/systems/*/waypoints/*/construction/supply", invalidation: func(m Mutation) []string {
+			waypoint := strings.TrimSuffix(m.Endpoint, "/construction/supply")
+			keys := append(ResponseKeys(waypoint), ResponseKeys(waypoint+"/construction")...)
+
+			var body struct {
+				ShipSymbol string `json:"shipSymbol"`
+			}
+			if data, err := json.Marshal(m.Body); err == nil {
+				decodeMutation(data, &body)
+			}
+			if body.ShipSymbol != "" {
+				keys = append(keys, agentShipKeys(body.ShipSymbol)...)
+			}
+			return keys
+		}},
+	}
+}
+
+// agentShipKeys returns the keys of the cached agent and ship, including the ship's cargo
+func agentShipKeys(shipSymbol string) []string {
+	keys := ResponseKeys("/my/agent")
+	return append(keys, responseCacheKey("/my/ships/"+shipSymbol, nil)+"*")
+}
+
+// waypointEndpoint returns the endpoint of a waypoint
+func waypointEndpoint(waypointSymbol string) string {
+	return "/systems/" + systemFromWaypoint(waypointSymbol) + "/waypoints/" + waypointSymbol
+}
+
+// decodeMutation decodes a request or response body, leaving value empty if it cannot be decoded
+func decodeMutation(data []byte, value interface{}) {
+	_ = json.Unmarshal(data, value)
+}
+
+// invalidateCache removes the cached data made stale by a successful mutating request
+func (c *Client) invalidateCache(method, endpoint string, body interface{}, response []byte) {
+	c.invalidationMu.RLock()
+	rules := c.invalidations
+	c.invalidationMu.RUnlock()
+
+	mutation := Mutation{Method: method, Endpoint: endpoint, Body: body, Response: response}
+	for _, rule := range rules {
+		if rule.method != method || !matchEndpoint(rule.pattern, endpoint) {
+			continue
+		}
+		for _, key := range rule.invalidation(mutation) {
+			c.invalidate(key)
+		}
+	}
+}
+
+// invalidate removes key, or every key with its prefix if it ends in "*", from CacheClient
+// and the cache directory
+func (c *Client) invalidate(key string) {
+	store := c.staticStore()
+
+	if prefix, ok := strings.CutSuffix(key, "*"); ok {
+		if removed := c.CacheClient.DeletePrefix(prefix); removed > 0 {
+			c.Logger.Debug("Invalidated cached data", "prefix", prefix, "removed", removed)
+		}
+		if store != nil {
+			if err := store.DeletePrefix(prefix); err != nil {
+				c.Logger.Warn("Failed to invalidate cached data", "prefix", prefix, "error", err)
+			}
+		}
+		return
+	}
+
+	c.CacheClient.Delete(key)
+	if store != nil {
+		if err := store.Delete(key); err != nil {
+			c.Logger.Warn("Failed to invalidate cached data", "key", key, "error", err)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newInvalidationTestClient(t *testing.T, cacheDir string) (*Client, func(path string) int) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+		case "/my/ships/SHIP-1/purchase", "/my/ships/SHIP-1/sell":
+			fmt.Fprint(w, `{"data":{"transaction":{"waypointSymbol":"X1-AB-C1","shipSymbol":"SHIP-1"}}}`)
+		case "/my/ships/SHIP-1/chart":
+			fmt.Fprint(w, `{"data":{"waypoint":{"symbol":"X1-AB-C2"}}}`)
+		default:
+			fmt.Fprint(w, `{"data":{}}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "token"}))
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.CacheDir = cacheDir
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close(t.Context()) })
+
+	return c, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[path]
+	}
+}
+
+func TestTradesInvalidateMarket(t *testing.T) {
+	c, requests := newInvalidationTestClient(t, "")
+
+	market := "/systems/X1-AB/waypoints/X1-AB-C1/market"
+	require.Nil(t, c.Get(market, nil, nil))
+	require.Nil(t, c.Get(market, nil, nil))
+	assert.Equal(t, 1, requests(market))
+
+	require.Nil(t, c.Post("/my/ships/SHIP-1/purchase", map[string]interface{}{"symbol": "FUEL", "units": 1}, nil, nil))
+	require.Nil(t, c.Get(market, nil, nil))
+	assert.Equal(t, 2, requests(market), "purchases invalidate the market")
+
+	require.Nil(t, c.Post("/my/ships/SHIP-1/sell", map[string]interface{}{"symbol": "FUEL", "units": 1}, nil, nil))
+	require.Nil(t, c.Get(market, nil, nil))
+	assert.Equal(t, 3, requests(market), "sales invalidate the market")
+}
+
+func TestChartInvalidatesWaypoints(t *testing.T) {
+	c, requests := newInvalidationTestClient(t, t.TempDir())
+
+	waypoint := "/systems/X1-AB/waypoints/X1-AB-C2"
+	waypoints := "/systems/X1-AB/waypoints"
+	jumpGate := "/systems/X1-CD/waypoints/X1-CD-I1/jump-gate"
+	for _, endpoint := range []string{waypoint, waypoints, jumpGate} {
+		require.Nil(t, c.Get(endpoint, map[string]string{"page": "1"}, nil))
+	}
+
+	require.Nil(t, c.Post("/my/ships/SHIP-1/chart", nil, nil, nil))
+
+	// Nothing stale is left on disk for the next restart
+	var body json.RawMessage
+	assert.False(t, c.LoadStatic(responseCacheKey(waypoint, map[string]string{"page": "1"}), &body))
+	assert.True(t, c.LoadStatic(responseCacheKey(jumpGate, map[string]string{"page": "1"}), &body))
+
+	for _, endpoint := range []string{waypoint, waypoints, jumpGate} {
+		require.Nil(t, c.Get(endpoint, map[string]string{"page": "1"}, nil))
+	}
+	assert.Equal(t, 2, requests(waypoint))
+	assert.Equal(t, 2, requests(waypoints))
+	assert.Equal(t, 1, requests(jumpGate), "other systems are kept")
+}
+
+func TestRegisterInvalidation(t *testing.T) {
+	c, _ := newInvalidationTestClient(t, "")
+
+	c.CacheClient.Set("route_SHIP-1_a", "route", cache.NoExpiration)
+	c.CacheClient.Set("route_SHIP-1_b", "route", cache.NoExpiration)
+	c.CacheClient.Set("route_SHIP-2_a", "route", cache.NoExpiration)
+
+	var mutations []Mutation
+	c.RegisterInvalidation("POST", "/my/ships/*/navigate", func(m Mutation) []string {
+		mutations = append(mutations, m)
+		return []string{"route_" + endpointSegments(m.Endpoint)[2] + "_*"}
+	})
+
+	require.Nil(t, c.Post("/my/ships/SHIP-1/navigate", map[string]string{"waypointSymbol": "X1-AB-C1"}, nil, nil))
+
+	require.Len(t, mutations, 1)
+	assert.Equal(t, "POST", mutations[0].Method)
+	assert.Equal(t, "/my/ships/SHIP-1/navigate", mutations[0].Endpoint)
+	assert.JSONEq(t, `{"data":{}}`, string(mutations[0].Response))
+
+	_, found := c.CacheClient.Get("route_SHIP-1_a")
+	assert.False(t, found)
+	_, found = c.CacheClient.Get("route_SHIP-1_b")
+	assert.False(t, found)
+	_, found = c.CacheClient.Get("route_SHIP-2_a")
+	assert.True(t, found, "keys without the prefix are kept")
+}
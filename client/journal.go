@@ -0,0 +1,163 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEntry records a successful mutating request and the state it left behind
+type JournalEntry struct {
+	Time     time.Time       `json:"time"`
+	Agent    string          `json:"agent"`
+	Method   string          `json:"method"`
+	Endpoint string          `json:"endpoint"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	// Transaction is the transaction reported in the response, if any
+	Transaction json.RawMessage `json:"transaction,omitempty"`
+	// Credits is the agent's credit balance after the request, if the response reported it
+	Credits *int64            `json:"credits,omitempty"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	DryRun  bool              `json:"dryRun,omitempty"`
+}
+
+// JournalSink receives an entry for every successful mutating request made by the client
+type JournalSink interface {
+	Record(ctx context.Context, entry JournalEntry) error
+	Close() error
+}
+
+// FileJournal appends journal entries to a file as JSON lines
+type FileJournal struct {
+	mu      sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+}
+
+// NewFileJournal opens or creates a JSONL journal file at path
+func NewFileJournal(path string) (*FileJournal, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal file: %w", err)
+	}
+
+	return &FileJournal{
+		file:    file,
+		encoder: json.NewEncoder(file),
+	}, nil
+}
+
+// Record appends an entry to the journal file
+func (j *FileJournal) Record(ctx context.Context, entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.encoder.Encode(entry); err != nil {
+		return fmt.Errorf("failed to write journal entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the journal file
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.file.Close()
+}
+
+// MemoryJournal keeps journal entries in memory
+type MemoryJournal struct {
+	mu      sync.RWMutex
+	entries []JournalEntry
+}
+
+// NewMemoryJournal creates an empty in-memory journal
+func NewMemoryJournal() *MemoryJournal {
+	return &MemoryJournal{}
+}
+
+// Record appends an entry to the journal
+func (j *MemoryJournal) Record(ctx context.Context, entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries = append(j.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of the recorded entries in the order they were recorded
+func (j *MemoryJournal) Entries() []JournalEntry {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	entries := make([]JournalEntry, len(j.entries))
+	copy(entries, j.entries)
+	return entries
+}
+
+// Close does nothing for an in-memory journal
+func (j *MemoryJournal) Close() error {
+	return nil
+}
+
+// recordJournal builds a journal entry for a successful mutating request and passes it
+// to the configured sink. Journal failures are logged and never fail the request.
+func (c *Client) recordJournal(ctx context.Context, method, endpoint string, body interface{}, response []byte) {
+	if c.journal == nil || method == "GET" {
+		return
+	}
+
+	entry := JournalEntry{
+		Time:     time.Now().UTC(),
+		Agent:    c.AgentSymbol,
+		Method:   method,
+		Endpoint: endpoint,
+		Labels:   GetMetricLabels(ctx),
+		DryRun:   c.dryRun != nil,
+	}
+	if len(entry.Labels) == 0 {
+		entry.Labels = nil
+	}
+
+	if body != nil {
+		if encoded, err := json.Marshal(body); err == nil {
+			entry.Request = encoded
+		}
+	}
+
+	if len(response) > 0 && json.Valid(response) {
+		entry.Response = response
+
+		var decoded struct {
+			Data struct {
+				Transaction json.RawMessage `json:"transaction"`
+				Agent       *struct {
+					Credits int64 `json:"credits"`
+				} `json:"agent"`
+			} `json:"data"`
+		}
+		if json.Unmarshal(response, &decoded) == nil {
+			entry.Transaction = decoded.Data.Transaction
+			if decoded.Data.Agent != nil {
+				entry.Credits = &decoded.Data.Agent.Credits
+			}
+		}
+	}
+
+	// Tokens and emails never reach the journal
+	entry.Request = c.redactor.Bytes(entry.Request)
+	entry.Response = c.redactor.Bytes(entry.Response)
+	entry.Transaction = c.redactor.Bytes(entry.Transaction)
+
+	if err := c.journal.Record(ctx, entry); err != nil {
+		c.Logger.Warn("Failed to record journal entry",
+			"method", method,
+			"endpoint", endpoint,
+			"error", err)
+	}
+}
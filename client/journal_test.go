@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordJournal(t *testing.T) {
+	journal := NewMemoryJournal()
+	c := &Client{AgentSymbol: "TEST", Logger: slog.Default(), journal: journal}
+
+	ctx := WithMetricLabel(context.Background(), "action_name", "sell")
+	response := []byte(`{"data":{"agent":{"symbol":"TEST","credits":1060},"transaction":{"tradeSymbol":"IRON_ORE","units":5,"totalPrice":60}}}`)
+
+	c.recordJournal(ctx, "GET", "/my/agent", nil, response)
+	c.recordJournal(ctx, "POST", "/my/ships/TEST-1/sell", map[string]interface{}{"symbol": "IRON_ORE", "units": 5}, response)
+
+	entries := journal.Entries()
+	assert.Len(t, entries, 1)
+
+	entry := entries[0]
+	assert.Equal(t, "TEST", entry.Agent)
+	assert.Equal(t, "/my/ships/TEST-1/sell", entry.Endpoint)
+	assert.Equal(t, "sell", entry.Labels["action_name"])
+	assert.JSONEq(t, `{"symbol":"IRON_ORE","units":5}`, string(entry.Request))
+	assert.JSONEq(t, `{"tradeSymbol":"IRON_ORE","units":5,"totalPrice":60}`, string(entry.Transaction))
+	if assert.NotNil(t, entry.Credits) {
+		assert.Equal(t, int64(1060), *entry.Credits)
+	}
+}
+
+func TestFileJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	journal, err := NewFileJournal(path)
+	assert.NoError(t, err)
+	assert.NoError(t, journal.Record(context.Background(), JournalEntry{Method: "POST", Endpoint: "/my/ships/TEST-1/dock"}))
+	assert.NoError(t, journal.Record(context.Background(), JournalEntry{Method: "POST", Endpoint: "/my/ships/TEST-1/orbit"}))
+	assert.NoError(t, journal.Close())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 2)
+
+	var entry JournalEntry
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+	assert.Equal(t, "/my/ships/TEST-1/orbit", entry.Endpoint)
+}
@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/jjkirkpatrick/spacetraders-client/internal/telemetry"
+)
+
+// AgentManagerOptions configures an AgentManager
+type AgentManagerOptions struct {
+	// ClientOptions are used for every agent's client. Symbol, Faction and Email are
+	// set per agent. Telemetry is initialised once and shared by all agents.
+	ClientOptions ClientOptions
+	// AccountToken registers new agents. If empty, the token stored under AccountTokenKey is used.
+	AccountToken string
+	// SharedRateLimiter makes all agents wait on one rate limiter. Otherwise each agent's
+	// token has its own limiter.
+	SharedRateLimiter bool
+}
+
+// AgentManager registers and manages many agents from one account token. Each agent has
+// its own Client and request queue, while the token store, journal and telemetry are shared.
+// Metrics from all agents are reported together, labelled by agent.
+type AgentManager struct {
+	options AgentManagerOptions
+	shared  *sharedResources
+
+	mu      sync.RWMutex
+	clients map[string]*Client
+	closed  bool
+}
+
+// NewAgentManager creates a manager and initialises the shared telemetry, if configured
+func NewAgentManager(options AgentManagerOptions) (*AgentManager, error) {
+	shared := &sharedResources{
+		tokenStore:   options.ClientOptions.TokenStore,
+		accountToken: options.AccountToken,
+	}
+	if shared.tokenStore == nil {
+		shared.tokenStore = NewFileTokenStore(DefaultTokenFile)
+	}
+
+	if options.SharedRateLimiter {
+		shared.rateLimiter = newRateLimiter(options.ClientOptions.RateLimitStrategy, options.ClientOptions.RequestsPerSecond)
+	}
+
+	if options.ClientOptions.TelemetryOptions != nil {
+		providers, err := newTelemetryProviders(context.Background(), options.ClientOptions.TelemetryOptions)
+		if err != nil {
+			return nil, err
+		}
+		shared.telemetryProviders = providers
+	}
+
+	return &AgentManager{
+		options: options,
+		shared:  shared,
+		clients: make(map[string]*Client),
+	}, nil
+}
+
+// AddAgent returns a client for the agent, registering it with the account token if the
+// token store has no token for symbol. Adding an agent twice returns the same client.
+func (m *AgentManager) AddAgent(symbol, faction, email string) (*Client, error) {
+	if c, ok, err := m.existingAgent(symbol); ok || err != nil {
+		return c, err
+	}
+
+	options := m.options.ClientOptions
+	options.Symbol = symbol
+	options.Faction = faction
+	options.Email = email
+
+	// Registering can take several rate-limited requests, so other agents are not held up
+	c, err := newClient(options, m.shared)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add agent %s: %w", symbol, err)
+	}
+
+	m.mu.Lock()
+	existing, ok := m.clients[symbol]
+	closed := m.closed
+	if !ok && !closed {
+		m.clients[symbol] = c
+	}
+	m.mu.Unlock()
+
+	switch {
+	case closed:
+		c.Close(context.Background())
+		return nil, fmt.Errorf("agent manager is closed")
+	case ok:
+		// Another caller added the agent first
+		c.Close(context.Background())
+		return existing, nil
+	}
+	return c, nil
+}
+
+// existingAgent returns the client already added for symbol, or an error if the manager is closed
+func (m *AgentManager) existingAgent(symbol string) (*Client, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, false, fmt.Errorf("agent manager is closed")
+	}
+	c, ok := m.clients[symbol]
+	return c, ok, nil
+}
+
+// Client returns the client for symbol, and false if the agent has not been added
+func (m *AgentManager) Client(symbol string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.clients[symbol]
+	return c, ok
+}
+
+// Agents returns the symbols of the managed agents in order
+func (m *AgentManager) Agents() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	symbols := make([]string, 0, len(m.clients))
+	for symbol := range m.clients {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
+
+// RemoveAgent closes the agent's client. Its token is kept in the token store.
+func (m *AgentManager) RemoveAgent(ctx context.Context, symbol string) error {
+	m.mu.Lock()
+	c, ok := m.clients[symbol]
+	delete(m.clients, symbol)
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return c.Close(ctx)
+}
+
+// TokenStore returns the token store shared by the managed agents
+func (m *AgentManager) TokenStore() TokenStore {
+	return m.shared.tokenStore
+}
+
+// MetricsHandler returns an http.Handler serving the metrics of every agent in the
+// Prometheus text format. It returns nil unless telemetry uses the Prometheus metric exporter.
+func (m *AgentManager) MetricsHandler() http.Handler {
+	if m.shared.telemetryProviders == nil {
+		return nil
+	}
+	return m.shared.telemetryProviders.MetricsHandler
+}
+
+// InMemoryTelemetry returns the in-memory exporter holding the telemetry of every agent,
+// or nil unless an exporter is set to telemetry.ExporterMemory
+func (m *AgentManager) InMemoryTelemetry() *telemetry.InMemoryExporter {
+	if m.shared.telemetryProviders == nil {
+		return nil
+	}
+	return m.shared.telemetryProviders.InMemory
+}
+
+// Close closes every agent's client, then the shared journal and telemetry
+func (m *AgentManager) Close(ctx context.Context) error {
+	m.mu.Lock()
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.closed = true
+	m.mu.Unlock()
+
+	var errs []error
+	for _, c := range clients {
+		if err := c.Close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if journal := m.options.ClientOptions.Journal; journal != nil {
+		if err := journal.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close journal: %w", err))
+		}
+	}
+
+	if m.shared.telemetryProviders != nil {
+		if err := m.shared.telemetryProviders.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,177 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/jjkirkpatrick/spacetraders-client/internal/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestAgentManager(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		auth := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+		case "/register":
+			if auth != "account-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error":{"code":401,"message":"account token required"}}`)
+				return
+			}
+			var body RegisterRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			fmt.Fprintf(w, `{"data":{"token":"token-%s"}}`, body.Symbol)
+		case "/my/agent":
+			fmt.Fprintf(w, `{"data":{"symbol":%q}}`, strings.TrimPrefix(auth, "token-"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.TokenStore = store
+	options.TelemetryOptions = &TelemetryOptions{
+		ServiceName:    "test",
+		MetricExporter: telemetry.ExporterMemory,
+		EnableMetrics:  true,
+	}
+
+	manager, err := NewAgentManager(AgentManagerOptions{
+		ClientOptions:     options,
+		AccountToken:      "account-token",
+		SharedRateLimiter: true,
+	})
+	require.NoError(t, err)
+	defer manager.Close(context.Background())
+
+	first, err := manager.AddAgent("FIRST", "COSMIC", "")
+	require.NoError(t, err)
+	second, err := manager.AddAgent("SECOND", "VOID", "")
+	require.NoError(t, err)
+
+	again, err := manager.AddAgent("FIRST", "COSMIC", "")
+	require.NoError(t, err)
+	assert.Same(t, first, again)
+
+	assert.Equal(t, []string{"FIRST", "SECOND"}, manager.Agents())
+	assert.Equal(t, "token-FIRST", first.GetToken())
+	assert.Equal(t, "token-SECOND", second.GetToken())
+	assert.Same(t, first.RateLimiter, second.RateLimiter)
+
+	entry, ok, err := store.Get("SECOND")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "token-SECOND", entry.Token)
+
+	for _, c := range []*Client{first, second} {
+		var response struct {
+			Data struct {
+				Symbol string `json:"symbol"`
+			} `json:"data"`
+		}
+		require.Nil(t, c.Get("/my/agent", nil, &response))
+		assert.Equal(t, c.AgentSymbol, response.Data.Symbol)
+	}
+
+	// Metrics from both agents are reported through the shared provider
+	requests, ok, err := manager.InMemoryTelemetry().Metric(context.Background(), "api_requests_total")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	agents := make(map[string]bool)
+	for _, point := range requests.Data.(metricdata.Sum[int64]).DataPoints {
+		if agent, ok := point.Attributes.Value("agent"); ok && point.Attributes.HasValue("endpoint") {
+			if endpoint, _ := point.Attributes.Value("endpoint"); endpoint.AsString() == "/my/agent" {
+				agents[agent.AsString()] = true
+			}
+		}
+	}
+	assert.Equal(t, map[string]bool{"FIRST": true, "SECOND": true}, agents)
+
+	require.NoError(t, manager.RemoveAgent(context.Background(), "FIRST"))
+	_, ok = manager.Client("FIRST")
+	assert.False(t, ok)
+}
+
+func TestAgentManager_SeparateRateLimiters(t *testing.T) {
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set("FIRST", TokenEntry{Token: "token-FIRST"}))
+	require.NoError(t, store.Set("SECOND", TokenEntry{Token: "token-SECOND"}))
+
+	options := DefaultClientOptions()
+	options.TokenStore = store
+
+	manager, err := NewAgentManager(AgentManagerOptions{ClientOptions: options})
+	require.NoError(t, err)
+	defer manager.Close(context.Background())
+
+	first, err := manager.AddAgent("FIRST", "COSMIC", "")
+	require.NoError(t, err)
+	second, err := manager.AddAgent("SECOND", "COSMIC", "")
+	require.NoError(t, err)
+
+	assert.NotSame(t, first.RateLimiter, second.RateLimiter)
+}
+
+func TestAgentManager_AddAgentDoesNotBlock(t *testing.T) {
+	registering := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+		case "/register":
+			var body RegisterRequest
+			json.NewDecoder(r.Body).Decode(&body)
+			if body.Symbol == "SLOW" {
+				close(registering)
+				<-release
+			}
+			fmt.Fprintf(w, `{"data":{"token":"token-%s"}}`, body.Symbol)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.TokenStore = NewMemoryTokenStore()
+
+	manager, err := NewAgentManager(AgentManagerOptions{ClientOptions: options, AccountToken: "account-token"})
+	require.NoError(t, err)
+	defer manager.Close(context.Background())
+
+	added := make(chan error, 1)
+	go func() {
+		_, err := manager.AddAgent("SLOW", "COSMIC", "")
+		added <- err
+	}()
+	<-registering
+
+	// Other agents are added and listed while SLOW is still registering
+	fast, err := manager.AddAgent("FAST", "VOID", "")
+	require.NoError(t, err)
+	assert.Equal(t, "token-FAST", fast.GetToken())
+	assert.Equal(t, []string{"FAST"}, manager.Agents())
+
+	close(release)
+	require.NoError(t, <-added)
+	assert.Equal(t, []string{"FAST", "SLOW"}, manager.Agents())
+}
@@ -1,7 +1,14 @@
 package client
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"math/rand"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/jjkirkpatrick/spacetraders-client/models"
@@ -14,6 +21,11 @@ type Paginator[T any] struct {
 	Meta          models.Meta // Use value instead of pointer to simplify usage
 	fetchPageFunc func(meta models.Meta) ([]T, models.Meta, error)
 	Error         error
+	// Prefetch is how many pages All fetches ahead of the page being yielded (default: 2)
+	Prefetch      int
+	retryPolicy   RetryPolicy
+	checkpoints   CheckpointStore
+	checkpointKey string
 }
 
 // NewPaginator creates a new Paginator instance with default pagination parameters.
@@ -25,6 +37,8 @@ func NewPaginator[T any](fetchFunc func(models.Meta) ([]T, models.Meta, error))
 		Meta:          defaultMeta,
 		fetchPageFunc: fetchFunc,
 		Error:         nil,
+		Prefetch:      2,
+		retryPolicy:   DefaultRetryPolicy(),
 	}
 }
 
@@ -71,78 +85,245 @@ func (p *Paginator[T]) getPreviousPage() (*Paginator[T], error) {
 	return p, nil    // Return the same paginator instance
 }
 
-// FetchAllPages fetches all data concurrently using 4 workers.
+// FetchAllPages fetches every page and returns the items in page order. If a page fails,
+// the items fetched before it are returned with the error.
 func (p *Paginator[T]) FetchAllPages() ([]T, error) {
-	// Get first page to determine total pages
-	firstPage, err := p.fetchFirstPage()
-	if err != nil {
-		return nil, err
+	var allData []T
+	for item, err := range p.All(context.Background()) {
+		if err != nil {
+			return allData, err
+		}
+		allData = append(allData, item)
 	}
+	return allData, nil
+}
 
-	totalPages := (firstPage.Meta.Total + firstPage.Meta.Limit - 1) / firstPage.Meta.Limit
+// pageResult is the outcome of fetching one page
+type pageResult[T any] struct {
+	data []T
+	meta models.Meta
+	// saved is set for pages loaded from the checkpoint
+	saved bool
+	err   error
+}
 
-	// Create channels for results and errors
-	results := make(chan []T, totalPages)
-	errors := make(chan error, totalPages)
-	pages := make(chan int, totalPages)
+// WithRetryPolicy retries pages that fail with transient errors under policy, usually the
+// client's c.RetryPolicy(). The default is DefaultRetryPolicy().
+func (p *Paginator[T]) WithRetryPolicy(policy RetryPolicy) *Paginator[T] {
+	p.retryPolicy = policy
+	return p
+}
 
-	// Calculate number of workers based on total pages
-	// Use min(totalPages, 8) to avoid creating more workers than needed
-	numWorkers := totalPages
-	if numWorkers > 12 {
-		numWorkers = 12
-	}
+// WithCheckpoint records each page in store under key as it is yielded. A crawl that was
+// interrupted resumes from the saved pages, fetching only the missing ones, and the
+// checkpoint is deleted once every page has been yielded. T must round-trip through JSON.
+func (p *Paginator[T]) WithCheckpoint(store CheckpointStore, key string) *Paginator[T] {
+	p.checkpoints = store
+	p.checkpointKey = key
+	return p
+}
 
-	// Start workers based on calculated number
-	for i := 0; i < numWorkers; i++ {
-		go func() {
-			for page := range pages {
-				paginator := &Paginator[T]{
-					Meta:          p.Meta,
-					fetchPageFunc: p.fetchPageFunc,
-				}
-				// Try up to 3 times
-				var data *Paginator[T]
-				var err error
-				for retries := 0; retries < 3; retries++ {
-					data, err = paginator.fetchPage(page)
-					if err == nil {
-						break
-					}
-					// Wait a bit before retrying
-					time.Sleep(time.Second * time.Duration(retries+1))
-				}
+// All returns an iterator over every item, in page order. The first page is fetched when
+// iteration starts; up to Prefetch following pages are fetched ahead while earlier items are
+// yielded, with requests paced by the client's rate limiter. Iteration stops at the first
+// error, which is yielded with the zero value of T, and when ctx is cancelled. Breaking out
+// of the loop stops fetching further pages.
+func (p *Paginator[T]) All(ctx context.Context) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		ctx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		// Pages still being fetched finish before iteration returns, so no worker outlives it
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		if err := ctx.Err(); err != nil {
+			yield(zero, err)
+			return
+		}
+
+		saved, err := p.loadCheckpoint()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		meta := p.Meta
+		meta.Page = 1
+		first, ok := saved[1]
+		if !ok {
+			first = p.fetchWithRetry(ctx, meta)
+		}
+		if first.err != nil {
+			yield(zero, first.err)
+			return
+		}
+		p.Data = first.data
+		p.Meta = first.meta
+		if err := p.checkpoint(1, first); err != nil {
+			yield(zero, err)
+			return
+		}
+		for _, item := range first.data {
+			if !yield(item, nil) {
+				return
+			}
+		}
+
+		totalPages := 1
+		if p.Meta.Limit > 0 {
+			totalPages = (p.Meta.Total + p.Meta.Limit - 1) / p.Meta.Limit
+		}
+
+		fetch := func(page int) chan pageResult[T] {
+			result := make(chan pageResult[T], 1)
+			if r, ok := saved[page]; ok {
+				result <- r
+				return result
+			}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				pageMeta := meta
+				pageMeta.Page = page
+				result <- p.fetchWithRetry(ctx, pageMeta)
+			}()
+			return result
+		}
+
+		// Pages are fetched ahead in a window and consumed in order
+		var pending []chan pageResult[T]
+		nextPage := 2
+		for ; nextPage <= totalPages && len(pending) < max(p.Prefetch, 1); nextPage++ {
+			pending = append(pending, fetch(nextPage))
+		}
+
+		for page := 2; len(pending) > 0; page++ {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var result pageResult[T]
+			select {
+			case <-ctx.Done():
+				yield(zero, ctx.Err())
+				return
+			case result = <-pending[0]:
+			}
 
-				if err != nil {
-					errors <- fmt.Errorf("failed to fetch page %d after 3 retries: %w", page, err)
-					continue
+			pending = pending[1:]
+			if nextPage <= totalPages {
+				pending = append(pending, fetch(nextPage))
+				nextPage++
+			}
+
+			if result.err != nil {
+				yield(zero, result.err)
+				return
+			}
+			if err := p.checkpoint(page, result); err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range result.data {
+				if !yield(item, nil) {
+					return
 				}
-				results <- data.Data
 			}
-		}()
+		}
+
+		if p.checkpoints != nil {
+			if err := p.checkpoints.Delete(p.checkpointKey); err != nil {
+				yield(zero, err)
+			}
+		}
+	}
+}
+
+// loadCheckpoint returns the pages saved by an earlier crawl, by page number. Pages saved
+// with a different page size are discarded.
+func (p *Paginator[T]) loadCheckpoint() (map[int]pageResult[T], error) {
+	if p.checkpoints == nil {
+		return nil, nil
 	}
 
-	// Send pages to workers (start at 2 since page 1 already fetched)
-	go func() {
-		for page := 2; page <= totalPages; page++ {
-			pages <- page
+	pages, err := p.checkpoints.Load(p.checkpointKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load checkpoint %s: %w", p.checkpointKey, err)
+	}
+
+	saved := make(map[int]pageResult[T], len(pages))
+	for _, page := range pages {
+		if page.Meta.Limit != p.Meta.Limit {
+			return nil, p.checkpoints.Delete(p.checkpointKey)
 		}
-		close(pages)
-	}()
 
-	// Collect results
-	var allData []T
-	allData = append(allData, firstPage.Data...)
+		var data []T
+		if err := json.Unmarshal(page.Items, &data); err != nil {
+			return nil, fmt.Errorf("failed to decode checkpoint %s page %d: %w", p.checkpointKey, page.Page, err)
+		}
+		saved[page.Page] = pageResult[T]{data: data, meta: page.Meta, saved: true}
+	}
+	return saved, nil
+}
+
+// checkpoint saves a fetched page to the checkpoint store, if any
+func (p *Paginator[T]) checkpoint(page int, result pageResult[T]) error {
+	if p.checkpoints == nil || result.saved {
+		return nil
+	}
+
+	items, err := json.Marshal(result.data)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint %s page %d: %w", p.checkpointKey, page, err)
+	}
+
+	meta := result.meta
+	meta.Page = page
+	if err := p.checkpoints.Save(p.checkpointKey, CheckpointPage{Page: page, Meta: meta, Items: items}); err != nil {
+		return fmt.Errorf("failed to save checkpoint %s page %d: %w", p.checkpointKey, page, err)
+	}
+	return nil
+}
+
+// fetchWithRetry fetches a page, retrying transient failures under the retry policy
+func (p *Paginator[T]) fetchWithRetry(ctx context.Context, meta models.Meta) pageResult[T] {
+	for attempt := 0; ; attempt++ {
+		data, pageMeta, err := p.fetchPageFunc(meta)
+		if err == nil {
+			return pageResult[T]{data: data, meta: pageMeta}
+		}
+		if attempt >= p.retryPolicy.MaxRetries || !isTransientError(err) {
+			return pageResult[T]{err: fmt.Errorf("failed to fetch page %d after %d attempts: %w", meta.Page, attempt+1, err)}
+		}
+
+		backoff := p.retryPolicy.backoff(attempt)
+		if p.retryPolicy.Jitter > 0 {
+			backoff += time.Duration(rand.Int63n(int64(p.retryPolicy.Jitter)))
+		}
 
-	// Collect remaining pages
-	for i := 1; i < totalPages; i++ {
 		select {
-		case data := <-results:
-			allData = append(allData, data...)
-		case err := <-errors:
-			return allData, err
+		case <-ctx.Done():
+			return pageResult[T]{err: ctx.Err()}
+		case <-time.After(backoff):
 		}
 	}
+}
 
-	return allData, nil
+// isTransientError reports whether a failed page is worth retrying. API errors other than
+// timeouts and server errors will fail again. Rate limits are not retried here, since the
+// request queue has already retried them under the same policy.
+func isTransientError(err error) bool {
+	var apiErr *models.APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	return apiErr.Code == 0 ||
+		apiErr.Code == http.StatusRequestTimeout ||
+		apiErr.Code >= http.StatusInternalServerError
 }
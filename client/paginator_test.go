@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPages returns a fetch function serving total items numbered from 1, recording the
+// pages requested
+func testPages(total int, fetched *atomic.Int32, fail map[int]bool) func(models.Meta) ([]int, models.Meta, error) {
+	return func(meta models.Meta) ([]int, models.Meta, error) {
+		fetched.Add(1)
+
+		// Later pages sometimes arrive first
+		time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+
+		if fail[meta.Page] {
+			return nil, meta, errors.New("server error")
+		}
+
+		var items []int
+		for i := (meta.Page-1)*meta.Limit + 1; i <= min(meta.Page*meta.Limit, total); i++ {
+			items = append(items, i)
+		}
+		meta.Total = total
+		return items, meta, nil
+	}
+}
+
+func newTestPaginator(fetch func(models.Meta) ([]int, models.Meta, error)) *Paginator[int] {
+	p := NewPaginator(fetch)
+	p.Meta.Limit = 10
+	return p.WithRetryPolicy(RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond})
+}
+
+func TestPaginatorAllYieldsInOrder(t *testing.T) {
+	var fetched atomic.Int32
+	p := newTestPaginator(testPages(95, &fetched, nil))
+	p.Prefetch = 4
+
+	var items []int
+	for item, err := range p.All(context.Background()) {
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+
+	require.Len(t, items, 95)
+	for i, item := range items {
+		assert.Equal(t, i+1, item)
+	}
+	assert.Equal(t, int32(10), fetched.Load())
+}
+
+func TestPaginatorAllStopsEarly(t *testing.T) {
+	var fetched atomic.Int32
+	p := newTestPaginator(testPages(1000, &fetched, nil))
+	p.Prefetch = 2
+
+	for item, err := range p.All(context.Background()) {
+		require.NoError(t, err)
+		if item == 15 {
+			break
+		}
+	}
+
+	// Page 1, page 2 being yielded and the prefetch window; nothing further
+	assert.LessOrEqual(t, fetched.Load(), int32(4))
+}
+
+func TestPaginatorAllStopsAtError(t *testing.T) {
+	var fetched atomic.Int32
+	p := newTestPaginator(testPages(50, &fetched, map[int]bool{3: true}))
+
+	var items []int
+	var iterErr error
+	for item, err := range p.All(context.Background()) {
+		if err != nil {
+			iterErr = err
+			continue
+		}
+		items = append(items, item)
+	}
+
+	require.Error(t, iterErr)
+	assert.Contains(t, iterErr.Error(), "page 3")
+	assert.Len(t, items, 20, "items before the failed page are yielded")
+
+	all, err := newTestPaginator(testPages(50, &fetched, map[int]bool{3: true})).FetchAllPages()
+	assert.Error(t, err)
+	assert.Len(t, all, 20)
+}
+
+func TestPaginatorAllHonoursContext(t *testing.T) {
+	var fetched atomic.Int32
+	p := newTestPaginator(testPages(1000, &fetched, nil))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var iterErr error
+	count := 0
+	for _, err := range p.All(ctx) {
+		if err != nil {
+			iterErr = err
+			break
+		}
+		count++
+		if count == 5 {
+			cancel()
+		}
+	}
+
+	assert.ErrorIs(t, iterErr, context.Canceled)
+	assert.Less(t, count, 1000)
+}
+
+func TestFetchAllPages(t *testing.T) {
+	var fetched atomic.Int32
+	items, err := newTestPaginator(testPages(42, &fetched, nil)).FetchAllPages()
+	require.NoError(t, err)
+	require.Len(t, items, 42)
+	assert.Equal(t, 1, items[0])
+	assert.Equal(t, 42, items[41])
+}
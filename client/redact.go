@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactedPlaceholder replaces every secret removed by a Redactor
+const RedactedPlaceholder = "[REDACTED]"
+
+// DefaultRedactionPatterns match the secrets the library handles: agent and account
+// tokens (JWTs), bearer authorization values and email addresses
+var DefaultRedactionPatterns = []string{
+	`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`,
+	`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`,
+	`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`,
+}
+
+// Redactor removes secrets from strings before they leave the process.
+// It matches a set of patterns plus the exact values of known secrets.
+// A nil Redactor returns its input unchanged.
+type Redactor struct {
+	mu       sync.RWMutex
+	patterns []*regexp.Regexp
+	secrets  []string
+}
+
+// NewRedactor creates a Redactor matching DefaultRedactionPatterns and any extra patterns
+func NewRedactor(patterns ...string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, pattern := range append(append([]string{}, DefaultRedactionPatterns...), patterns...) {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// AddSecret registers an exact value that must always be redacted, such as a token
+func (r *Redactor) AddSecret(secret string) {
+	if r == nil || secret == "" {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.secrets {
+		if existing == secret {
+			return
+		}
+	}
+	r.secrets = append(r.secrets, secret)
+}
+
+// String returns s with every secret replaced by RedactedPlaceholder
+func (r *Redactor) String(s string) string {
+	if r == nil || s == "" {
+		return s
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, RedactedPlaceholder)
+	}
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, RedactedPlaceholder)
+	}
+	return s
+}
+
+// Bytes returns b with every secret replaced by RedactedPlaceholder.
+// Redacting a JSON document keeps it valid, as no pattern matches quotes.
+func (r *Redactor) Bytes(b []byte) []byte {
+	if r == nil || len(b) == 0 {
+		return b
+	}
+	return []byte(r.String(string(b)))
+}
+
+// Attr returns attr with secrets removed from its value, descending into groups.
+// Values of other kinds are formatted as strings when their text contains a secret.
+func (r *Redactor) Attr(attr slog.Attr) slog.Attr {
+	if r == nil {
+		return attr
+	}
+
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(r.String(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, groupAttr := range group {
+			redacted[i] = r.Attr(groupAttr)
+		}
+		attr.Value = slog.GroupValue(redacted...)
+	case slog.KindAny:
+		text := fmt.Sprintf("%+v", value.Any())
+		if redacted := r.String(text); redacted != text {
+			attr.Value = slog.StringValue(redacted)
+		} else {
+			attr.Value = value
+		}
+	default:
+		attr.Value = value
+	}
+	return attr
+}
+
+// RedactingHandler is an slog.Handler that removes secrets from each record's message
+// and attributes before passing it to the wrapped handler
+type RedactingHandler struct {
+	handler  slog.Handler
+	redactor *Redactor
+}
+
+// NewRedactingHandler wraps handler so every record is redacted by redactor
+func NewRedactingHandler(handler slog.Handler, redactor *Redactor) *RedactingHandler {
+	return &RedactingHandler{
+		handler:  handler,
+		redactor: redactor,
+	}
+}
+
+// Enabled reports whether the wrapped handler handles records at the given level
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+// Handle redacts the record and passes it to the wrapped handler
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.redactor.String(r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactor.Attr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+// WithAttrs returns a handler whose attributes are redacted once up front
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactor.Attr(attr)
+	}
+	return NewRedactingHandler(h.handler.WithAttrs(redacted), h.redactor)
+}
+
+// WithGroup returns a redacting handler for the given group
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return NewRedactingHandler(h.handler.WithGroup(name), h.redactor)
+}
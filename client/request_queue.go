@@ -3,12 +3,14 @@ package client
 import (
 	"context"
 	"math/rand"
+	"reflect"
 	"sync"
 	"time"
 
 	"github.com/jjkirkpatrick/spacetraders-client/models"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RequestExecutor is an interface for executing API requests
@@ -35,6 +37,7 @@ type apiRequest struct {
 // apiResponse represents the response from a processed request
 type apiResponse struct {
 	err         *models.APIError
+	result      interface{}   // Decoded by the worker, copied into the caller's result on receipt
 	queueTime   time.Duration // Time spent in queue
 	processTime time.Duration // Time spent processing
 }
@@ -46,6 +49,8 @@ type RequestQueue struct {
 	cancel       context.CancelFunc
 	wg           sync.WaitGroup
 	executor     RequestExecutor
+	retryPolicy  RetryPolicy
+	timeout      time.Duration // Limit on a whole request, including queueing and retries
 	processingCh chan struct{} // Channel to control processing rate
 
 	// Metrics tracking
@@ -55,11 +60,44 @@ type RequestQueue struct {
 	requestsProcessed int64
 }
 
-// Maximum number of retries for rate-limited requests
-const maxRetries = 3
+// RetryPolicy controls how rate-limited requests are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry, doubled for each further retry
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between retries, including waits suggested by the API
+	MaxBackoff time.Duration
+	// Jitter is the upper bound of a random delay added to each wait
+	Jitter time.Duration
+}
+
+// DefaultRetryPolicy returns the default retry policy: 3 retries starting at 500ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Jitter:         50 * time.Millisecond,
+	}
+}
+
+// backoff returns the wait before the given retry, starting at 0
+func (p RetryPolicy) backoff(retryCount int) time.Duration {
+	backoff := p.InitialBackoff * time.Duration(1<<retryCount) // 500ms, 1s, 2s, etc.
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+	return backoff
+}
 
-// NewRequestQueue creates a new request queue with the specified buffer size
+// NewRequestQueue creates a new request queue with the specified buffer size and the default retry policy
 func NewRequestQueue(ctx context.Context, executor RequestExecutor, bufferSize int) *RequestQueue {
+	return newRequestQueue(ctx, executor, bufferSize, DefaultRetryPolicy(), 0)
+}
+
+// newRequestQueue creates a new request queue retrying rate-limited requests under policy
+func newRequestQueue(ctx context.Context, executor RequestExecutor, bufferSize int, policy RetryPolicy, timeout time.Duration) *RequestQueue {
 	queueCtx, cancel := context.WithCancel(ctx)
 
 	queue := &RequestQueue{
@@ -67,6 +105,8 @@ func NewRequestQueue(ctx context.Context, executor RequestExecutor, bufferSize i
 		ctx:          queueCtx,
 		cancel:       cancel,
 		executor:     executor,
+		retryPolicy:  policy,
+		timeout:      timeout,
 		processingCh: make(chan struct{}, 1), // Buffer of 1 to allow non-blocking sends
 	}
 
@@ -111,14 +151,29 @@ func (q *RequestQueue) processRequests() {
 				req.startedAt = time.Now()
 				queueTime := req.startedAt.Sub(req.enqueuedAt)
 
+				recordQueueWait(req, q.redactor())
+
 				// Process the request with retries for rate limit errors
 				var err *models.APIError
 				var processTime time.Duration
+				retries := 0
+
+				// The caller may stop waiting while the request is in flight, so the response is
+				// decoded into a value only the worker writes
+				result, owned := ownResult(req.result)
 
 				// Try the request with retries
-				for retryCount := 0; retryCount <= maxRetries; retryCount++ {
+				for retryCount := 0; retryCount <= q.retryPolicy.MaxRetries; retryCount++ {
+					retries = retryCount
+
+					// Requests that timed out or were cancelled while queued or backing off are not sent
+					if req.ctx.Err() != nil {
+						err = contextError(req.ctx)
+						break
+					}
+
 					// Execute the request
-					err = q.executor.executeRequest(req.ctx, req.method, req.endpoint, req.body, req.queryParams, req.result)
+					err = q.executor.executeRequest(req.ctx, req.method, req.endpoint, req.body, req.queryParams, result)
 
 					// If successful or not a rate limit error, break out of retry loop
 					if err == nil || err.Code != 429 {
@@ -161,10 +216,10 @@ func (q *RequestQueue) processRequests() {
 					}
 
 					// This is a rate limit error, prepare to retry
-					if retryCount < maxRetries {
-						// Calculate backoff time - start with 500ms and increase exponentially
+					if retryCount < q.retryPolicy.MaxRetries {
+						// Calculate backoff time - increase exponentially under the retry policy
 						// Also use the retryAfter value from the API if available
-						backoff := time.Duration(500*time.Millisecond) * time.Duration(1<<retryCount) // 500ms, 1s, 2s, etc.
+						backoff := q.retryPolicy.backoff(retryCount)
 
 						// Check if the API provided a retryAfter value
 						if err.Data != nil {
@@ -172,7 +227,7 @@ func (q *RequestQueue) processRequests() {
 								// Convert to duration (API returns milliseconds)
 								apiBackoff := time.Duration(retryAfter * float64(time.Millisecond))
 								// Use the API's suggestion if it's reasonable
-								if apiBackoff < 5*time.Second {
+								if q.retryPolicy.MaxBackoff <= 0 || apiBackoff < q.retryPolicy.MaxBackoff {
 									backoff = apiBackoff
 								}
 							}
@@ -189,8 +244,9 @@ func (q *RequestQueue) processRequests() {
 						}
 
 						// Add a small jitter to prevent thundering herd
-						jitter := time.Duration(rand.Int63n(int64(50 * time.Millisecond)))
-						backoff += jitter
+						if q.retryPolicy.Jitter > 0 {
+							backoff += time.Duration(rand.Int63n(int64(q.retryPolicy.Jitter)))
+						}
 
 						// Log the retry
 						if client, ok := q.executor.(*Client); ok {
@@ -210,6 +266,8 @@ func (q *RequestQueue) processRequests() {
 								Message: "request cancelled during retry backoff: client is shutting down",
 							}
 							break
+						case <-req.ctx.Done():
+							// The request timed out or was cancelled during backoff, reported on the next attempt
 						case <-time.After(backoff):
 							// Continue to retry
 						}
@@ -243,6 +301,8 @@ func (q *RequestQueue) processRequests() {
 					}
 				}
 
+				trace.SpanFromContext(req.ctx).SetAttributes(attribute.Int("spacetraders.retries", retries))
+
 				// Record when processing finished
 				req.finishedAt = time.Now()
 				processTime = req.finishedAt.Sub(req.startedAt)
@@ -255,11 +315,15 @@ func (q *RequestQueue) processRequests() {
 				q.mu.Unlock()
 
 				// Send the response back to the caller
-				req.responseCh <- apiResponse{
+				response := apiResponse{
 					err:         err,
 					queueTime:   queueTime,
 					processTime: processTime,
 				}
+				if owned {
+					response.result = result
+				}
+				req.responseCh <- response
 
 				// Signal that processing is complete
 				select {
@@ -282,7 +346,21 @@ func (q *RequestQueue) Enqueue(method, endpoint string, body interface{}, queryP
 
 // EnqueueWithContext adds a request to the queue with context and returns the result.
 // The context can contain custom metric labels via WithMetricLabels.
-func (q *RequestQueue) EnqueueWithContext(ctx context.Context, method, endpoint string, body interface{}, queryParams map[string]string, result interface{}) *models.APIError {
+func (q *RequestQueue) EnqueueWithContext(ctx context.Context, method, endpoint string, body interface{}, queryParams map[string]string, result interface{}) (apiErr *models.APIError) {
+	// The timeout covers the queue wait, every attempt and the backoff between them
+	if q.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.timeout)
+		defer cancel()
+	}
+
+	// The request span covers both the queue wait and HTTP execution
+	redactor := q.redactor()
+	ctx, span := startRequestSpan(ctx, method, endpoint, redactor)
+	defer func() {
+		endSpanWithError(span, apiErr, redactor)
+	}()
+
 	// Create a response channel
 	responseCh := make(chan apiResponse, 1)
 
@@ -302,6 +380,8 @@ func (q *RequestQueue) EnqueueWithContext(ctx context.Context, method, endpoint
 	select {
 	case q.requests <- req:
 		// Request added to queue
+	case <-ctx.Done():
+		return contextError(ctx)
 	case <-q.ctx.Done():
 		// Context cancelled, return error
 		return &models.APIError{
@@ -323,7 +403,13 @@ func (q *RequestQueue) EnqueueWithContext(ctx context.Context, method, endpoint
 			client.queueWaitTime.Record(client.context, resp.queueTime.Seconds(), metric.WithAttributes(attrs...))
 			client.queueProcessTime.Record(client.context, resp.processTime.Seconds(), metric.WithAttributes(attrs...))
 		}
+		if resp.result != nil {
+			reflect.ValueOf(result).Elem().Set(reflect.ValueOf(resp.result).Elem())
+		}
 		return resp.err
+	case <-ctx.Done():
+		// The worker skips the request, or abandons it if it is in flight
+		return contextError(ctx)
 	case <-q.ctx.Done():
 		return &models.APIError{
 			Code:    499, // Client closed request
@@ -332,6 +418,16 @@ func (q *RequestQueue) EnqueueWithContext(ctx context.Context, method, endpoint
 	}
 }
 
+// ownResult allocates a zero value of the type result points to, for the worker to decode a
+// response into. It reports false, returning result unchanged, if result is not a pointer.
+func ownResult(result interface{}) (interface{}, bool) {
+	value := reflect.ValueOf(result)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return result, false
+	}
+	return reflect.New(value.Type().Elem()).Interface(), true
+}
+
 // Shutdown gracefully shuts down the request queue
 func (q *RequestQueue) Shutdown() {
 	// Signal the worker to stop
@@ -226,3 +226,41 @@ func TestRequestQueue_ContextPropagation(t *testing.T) {
 	assert.Equal(t, "sell_cargo", capturedLabels["action_name"])
 	assert.Equal(t, "hauler", capturedLabels["ship_role"])
 }
+
+func TestRequestQueue_AbandonedResultNotWritten(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan struct{})
+	mockExec := &mockExecutor{
+		executeRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, queryParams map[string]string, result interface{}) *models.APIError {
+			if endpoint == "/slow" {
+				close(started)
+				<-release
+				defer close(done)
+			}
+			*result.(*string) = "decoded " + endpoint
+			return nil
+		},
+	}
+
+	queue := NewRequestQueue(context.Background(), mockExec, 10)
+	defer queue.Shutdown()
+
+	var result string
+	assert.Nil(t, queue.Enqueue("GET", "/fast", nil, nil, &result))
+	assert.Equal(t, "decoded /fast", result)
+
+	// The caller gives up while the worker is still decoding; its result is left alone
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	result = "unchanged"
+	err := queue.EnqueueWithContext(ctx, "GET", "/slow", nil, nil, &result)
+	assert.NotNil(t, err)
+
+	close(release)
+	<-done
+	assert.Equal(t, "unchanged", result)
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ResetEvent describes a game reset the client recovered from
+type ResetEvent struct {
+	Agent string
+	// ResetDate is the server's resetDate after the reset, empty if it could not be fetched
+	ResetDate string
+	// RecoveredAt is when the agent was re-registered
+	RecoveredAt time.Time
+}
+
+// resetRetryKey marks a request that is being retried after recovering from a reset
+type resetRetryKey struct{}
+
+// OnReset registers a callback run after the client recovers from a game reset.
+// Callbacks run in their own goroutine, so they may make requests with the client.
+func (c *Client) OnReset(callback func(ResetEvent)) {
+	c.resetMu.Lock()
+	defer c.resetMu.Unlock()
+
+	c.resetCallbacks = append(c.resetCallbacks, callback)
+}
+
+// handleGameReset reacts to a token version mismatch returned for a request made with
+// staleToken. It reports whether the client recovered and the request should be retried.
+func (c *Client) handleGameReset(ctx context.Context, staleToken string) bool {
+	// Requests made while recovering, and retries after recovering, are never recovered again
+	if !c.autoRecoverReset || ctx.Value(resetRetryKey{}) != nil || c.recovering.Load() {
+		c.resetStaticStore("")
+		c.notifyGameReset()
+		return false
+	}
+
+	if err := c.recoverFromReset(staleToken); err != nil {
+		c.Logger.Error("Failed to recover from game reset", "error", err)
+		c.resetStaticStore("")
+		c.notifyGameReset()
+		return false
+	}
+	return true
+}
+
+// notifyGameReset signals GameResetCh without blocking
+func (c *Client) notifyGameReset() {
+	select {
+	case c.GameResetCh <- struct{}{}:
+		// Successfully sent notification
+	default:
+		// Channel buffer is full, which means a notification has already been sent
+		// This is fine, we just want to ensure at least one notification is sent
+	}
+}
+
+// recoverFromReset clears state from before the reset and re-registers the agent with the
+// account token. Nothing is done if another request already replaced staleToken.
+func (c *Client) recoverFromReset(staleToken string) error {
+	c.resetMu.Lock()
+	defer c.resetMu.Unlock()
+
+	if c.currentToken() != staleToken {
+		return nil
+	}
+
+	c.Logger.Warn("Recovering from game reset")
+	c.recovering.Store(true)
+	defer c.recovering.Store(false)
+
+	c.CacheClient.Clear()
+	if c.dryRun != nil {
+		c.dryRun = newDryRunState()
+	}
+
+	resetDate := c.currentResetDate()
+	c.resetStaticStore(resetDate)
+
+	// The stored token is the stale one, so the agent is registered again. The stale token
+	// is kept until the new one is issued.
+	if err := validateRegistration(c.faction, c.AgentSymbol); err != nil {
+		return fmt.Errorf("failed to re-register agent: %w", err)
+	}
+	if err := c.registerAgent(c.faction, c.AgentSymbol, c.email); err != nil {
+		return fmt.Errorf("failed to re-register agent: %w", err)
+	}
+
+	if err := c.discardStaleTokens(resetDate); err != nil {
+		c.Logger.Warn("Failed to discard stale tokens", "error", err)
+	}
+
+	event := ResetEvent{
+		Agent:       c.AgentSymbol,
+		ResetDate:   resetDate,
+		RecoveredAt: time.Now(),
+	}
+	c.Logger.Info("Re-registered agent after game reset", "resetDate", resetDate)
+
+	callbacks := append([]func(ResetEvent){}, c.resetCallbacks...)
+	go func() {
+		for _, callback := range callbacks {
+			callback(event)
+		}
+	}()
+	return nil
+}
+
+// discardStaleTokens removes agent tokens issued before resetDate from the token store.
+// Every agent token is invalidated by a reset, while the account token stays valid. Tokens
+// without a reset date, and every token when resetDate is unknown, are kept, since they may
+// belong to other agents registered after the reset.
+func (c *Client) discardStaleTokens(resetDate string) error {
+	if resetDate == "" {
+		return nil
+	}
+	store := c.tokens()
+
+	entries, err := store.List()
+	if err != nil {
+		return fmt.Errorf("failed to list stored tokens: %w", err)
+	}
+
+	for symbol, entry := range entries {
+		if symbol == AccountTokenKey {
+			continue
+		}
+		if entry.ResetDate == "" || entry.ResetDate == resetDate {
+			continue
+		}
+		if err := store.Delete(symbol); err != nil {
+			return fmt.Errorf("failed to discard stale token for %s: %w", symbol, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutoRecoverReset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		auth := r.Header.Get("Authorization")
+
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+		case "/register":
+			if auth != "Bearer account-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprint(w, `{"error":{"code":401,"message":"account token required"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"token":"new-token","agent":{"symbol":"TEST"}}}`)
+		case "/my/agent":
+			if auth != "Bearer new-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintf(w, `{"error":{"code":401,"message":"%s"}}`, TokenVersionMismatchPattern)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"symbol":"TEST","credits":175000}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set(AccountTokenKey, TokenEntry{Token: "account-token"}))
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "stale-token", ResetDate: "2026-10-11"}))
+	require.NoError(t, store.Set("OTHER", TokenEntry{Token: "other-stale-token", ResetDate: "2026-10-11"}))
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.AutoRecoverReset = true
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	c.CacheClient.Set("stale", true, cache.NoExpiration)
+
+	events := make(chan ResetEvent, 1)
+	c.OnReset(func(event ResetEvent) {
+		events <- event
+	})
+
+	var response struct {
+		Data struct {
+			Credits int64 `json:"credits"`
+		} `json:"data"`
+	}
+	apiErr := c.Get("/my/agent", nil, &response)
+	require.Nil(t, apiErr)
+	assert.Equal(t, int64(175000), response.Data.Credits)
+	assert.Equal(t, "new-token", c.GetToken())
+	assert.Equal(t, 0, c.CacheClient.Size())
+	assert.False(t, c.IsGameReset())
+
+	entry, _, err := store.Get("TEST")
+	require.NoError(t, err)
+	assert.Equal(t, TokenEntry{Token: "new-token", ResetDate: "2026-10-18"}, entry)
+
+	_, exists, err := store.Get("OTHER")
+	require.NoError(t, err)
+	assert.False(t, exists, "stale tokens should be discarded")
+
+	select {
+	case event := <-events:
+		assert.Equal(t, "TEST", event.Agent)
+		assert.Equal(t, "2026-10-18", event.ResetDate)
+	case <-time.After(time.Second):
+		t.Fatal("OnReset callback was not called")
+	}
+}
+
+func TestAutoRecoverReset_Disabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprintf(w, `{"error":{"code":401,"message":"%s"}}`, TokenVersionMismatchPattern)
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "stale-token"}))
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	apiErr := c.Get("/my/agent", nil, nil)
+	require.NotNil(t, apiErr)
+	assert.True(t, c.IsGameReset())
+	assert.Equal(t, "stale-token", c.GetToken())
+}
+
+func TestAutoRecoverReset_StatusUnavailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"code":503,"message":"maintenance"}}`)
+		case "/register":
+			fmt.Fprint(w, `{"data":{"token":"new-token","agent":{"symbol":"TEST"}}}`)
+		default:
+			if r.Header.Get("Authorization") != "Bearer new-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				fmt.Fprintf(w, `{"error":{"code":401,"message":"%s"}}`, TokenVersionMismatchPattern)
+				return
+			}
+			fmt.Fprint(w, `{"data":{}}`)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set(AccountTokenKey, TokenEntry{Token: "account-token"}))
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "stale-token", ResetDate: "2026-10-11"}))
+	require.NoError(t, store.Set("OTHER", TokenEntry{Token: "other-token", ResetDate: "2026-10-18"}))
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.AutoRecoverReset = true
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	require.Nil(t, c.Get("/my/agent", nil, nil))
+	assert.Equal(t, "new-token", c.GetToken())
+
+	// Without the reset date no token can be known to be stale
+	entry, exists, err := store.Get("OTHER")
+	require.NoError(t, err)
+	require.True(t, exists, "tokens are kept when the reset date is unknown")
+	assert.Equal(t, "other-token", entry.Token)
+}
+
+func TestAutoRecoverReset_RegistrationFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+		case "/register":
+			w.WriteHeader(http.StatusConflict)
+			fmt.Fprint(w, `{"error":{"code":4111,"message":"agent symbol has already been claimed"}}`)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprintf(w, `{"error":{"code":401,"message":"%s"}}`, TokenVersionMismatchPattern)
+		}
+	}))
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set(AccountTokenKey, TokenEntry{Token: "account-token"}))
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "stale-token", ResetDate: "2026-10-11"}))
+	require.NoError(t, store.Set("OTHER", TokenEntry{Token: "other-stale-token", ResetDate: "2026-10-11"}))
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.AutoRecoverReset = true
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	require.NotNil(t, c.Get("/my/agent", nil, nil))
+	assert.True(t, c.IsGameReset())
+	assert.Equal(t, "stale-token", c.GetToken())
+
+	// Nothing is discarded until the agent has been re-registered
+	tokens, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, tokens, 3)
+}
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// CacheUntilReset keeps responses until the next game reset. They are also kept on disk
+// when ClientOptions.CacheDir is set.
+const CacheUntilReset time.Duration = -1
+
+// ResponseNamespace is the CacheClient namespace holding cached GET responses, as
+// json.RawMessage bodies
+const ResponseNamespace = "responses"
+
+// CacheRule sets how long GET responses for endpoints matching Pattern are cached.
+// Pattern segments are matched against the endpoint's path segments: "*" matches any one
+// segment and a final "**" matches any remaining segments. A TTL of 0 disables caching.
+type CacheRule struct {
+	Pattern string
+	TTL     time.Duration
+}
+
+// CachePolicy maps endpoints to how long their GET responses are cached. The first
+// matching rule applies, and responses for endpoints matching no rule are not cached.
+type CachePolicy struct {
+	Rules []CacheRule
+}
+
+// DefaultCachePolicy caches systems, waypoints and jump gates until the next reset and
+// markets and shipyards for a few seconds. The agent's own data under /my is never cached.
+func DefaultCachePolicy() CachePolicy {
+	return CachePolicy{Rules: []CacheRule{
+		{Pattern: "/my/**", TTL: 0},
+		{Pattern: "/systems/*/waypoints/*/market", TTL: 10 * time.Second},
+		{Pattern: "/systems/*/waypoints/*/shipyard", TTL: 10 * time.Second},
+		{Pattern: "/systems/*/waypoints/*/construction", TTL: 0},
+		{Pattern: "/systems/*/waypoints/*/jump-gate", TTL: CacheUntilReset},
+		{Pattern: "/systems/*/waypoints/*", TTL: CacheUntilReset},
+		{Pattern: "/systems/*/waypoints", TTL: CacheUntilReset},
+		{Pattern: "/systems/*", TTL: CacheUntilReset},
+		{Pattern: "/systems", TTL: CacheUntilReset},
+	}}
+}
+
+// match returns the first rule matching endpoint
+func (p CachePolicy) match(endpoint string) (CacheRule, bool) {
+	for _, rule := range p.Rules {
+		if matchEndpoint(rule.Pattern, endpoint) {
+			return rule, true
+		}
+	}
+	return CacheRule{}, false
+}
+
+// matchEndpoint reports whether the path segments of endpoint match pattern
+func matchEndpoint(pattern, endpoint string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	endpointSegments := strings.Split(strings.Trim(endpoint, "/"), "/")
+
+	for i, segment := range patternSegments {
+		if segment == "**" && i == len(patternSegments)-1 {
+			return true
+		}
+		if i >= len(endpointSegments) {
+			return false
+		}
+		if segment != "*" && segment != endpointSegments[i] {
+			return false
+		}
+		if segment == "*" && endpointSegments[i] == "" {
+			return false
+		}
+	}
+	return len(patternSegments) == len(endpointSegments)
+}
+
+// responseCacheKey identifies a GET response in CacheClient by endpoint and query parameters
+func responseCacheKey(endpoint string, queryParams map[string]string) string {
+	key := ResponseNamespace + cache.NamespaceSeparator + "GET " + endpoint
+	if len(queryParams) == 0 {
+		return key
+	}
+
+	values := url.Values{}
+	for key, value := range queryParams {
+		values.Set(key, value)
+	}
+	// Encode sorts by key, so the same parameters always give the same key
+	return key + "?" + values.Encode()
+}
+
+// cachedGet answers a GET request from the response cache, or sends it through the
+// request queue and caches the response as the cache policy allows
+func (c *Client) cachedGet(ctx context.Context, endpoint string, queryParams map[string]string, result interface{}) *models.APIError {
+	rule, ok := c.cachePolicy.match(endpoint)
+	if !ok || rule.TTL == 0 {
+		return c.requestQueue.EnqueueWithContext(ctx, "GET", endpoint, nil, queryParams, result)
+	}
+
+	key := responseCacheKey(endpoint, queryParams)
+	if body, found := c.lookupResponse(key, rule); found {
+		if result == nil || json.Unmarshal(body, result) == nil {
+			// Dry runs are simulated against cached responses like fetched ones
+			if c.dryRun != nil {
+				c.dryRun.observe(endpoint, body)
+			}
+			c.recordCacheLookup(ctx, rule, true)
+			return nil
+		}
+		c.CacheClient.Delete(key)
+	}
+	c.recordCacheLookup(ctx, rule, false)
+
+	return c.requestQueue.EnqueueWithContext(ctx, "GET", endpoint, nil, queryParams, result)
+}
+
+// lookupResponse returns the cached body stored under key, loading responses cached until
+// the reset from disk when they are not in memory
+func (c *Client) lookupResponse(key string, rule CacheRule) (json.RawMessage, bool) {
+	if cached, found := c.CacheClient.Get(key); found {
+		if body, ok := cached.(json.RawMessage); ok {
+			return body, true
+		}
+	}
+
+	if rule.TTL != CacheUntilReset {
+		return nil, false
+	}
+	var body json.RawMessage
+	if !c.LoadStatic(key, &body) {
+		return nil, false
+	}
+	c.CacheClient.Set(key, body, cache.NoExpiration)
+	return body, true
+}
+
+// cacheResponse stores the body of a successful GET request as the cache policy allows
+func (c *Client) cacheResponse(endpoint string, queryParams map[string]string, body []byte) {
+	rule, ok := c.cachePolicy.match(endpoint)
+	if !ok || rule.TTL == 0 {
+		return
+	}
+
+	key := responseCacheKey(endpoint, queryParams)
+	if rule.TTL == CacheUntilReset {
+		c.CacheClient.Set(key, json.RawMessage(body), cache.NoExpiration)
+		c.SaveStatic(key, json.RawMessage(body))
+		return
+	}
+	c.CacheClient.Set(key, json.RawMessage(body), rule.TTL)
+}
+
+// recordCacheLookup counts a response cache hit or miss, labelled with the rule's pattern
+func (c *Client) recordCacheLookup(ctx context.Context, rule CacheRule, hit bool) {
+	if c.meter == nil {
+		return // Telemetry is disabled
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("agent", c.AgentSymbol),
+		attribute.String("endpoint", rule.Pattern),
+	}
+	for key, value := range GetMetricLabels(ctx) {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+
+	counter := c.cacheMissCounter
+	if hit {
+		counter = c.cacheHitCounter
+	}
+	counter.Add(c.context, 1, metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,245 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchEndpoint(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		endpoint string
+		want     bool
+	}{
+		{"/systems", "/systems", true},
+		{"/systems/*", "/systems/X1-AB", true},
+		{"/systems/*", "/systems", false},
+		{"/systems/*", "/systems/X1-AB/waypoints", false},
+		{"/systems/*/waypoints/*/market", "/systems/X1-AB/waypoints/X1-AB-C1/market", true},
+		{"/systems/*/waypoints/*/market", "/systems/X1-AB/waypoints/X1-AB-C1/shipyard", false},
+		{"/my/**", "/my/ships/SHIP-1/nav", true},
+		{"/my/**", "/my", true},
+		{"/my/**", "/factions", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchEndpoint(tt.pattern, tt.endpoint), "%s against %s", tt.pattern, tt.endpoint)
+	}
+}
+
+func TestDefaultCachePolicy(t *testing.T) {
+	policy := DefaultCachePolicy()
+
+	ttl := func(endpoint string) time.Duration {
+		rule, ok := policy.match(endpoint)
+		if !ok {
+			return 0
+		}
+		return rule.TTL
+	}
+
+	assert.Equal(t, CacheUntilReset, ttl("/systems"))
+	assert.Equal(t, CacheUntilReset, ttl("/systems/X1-AB/waypoints/X1-AB-C1"))
+	assert.Equal(t, CacheUntilReset, ttl("/systems/X1-AB/waypoints/X1-AB-I1/jump-gate"))
+	assert.Equal(t, 10*time.Second, ttl("/systems/X1-AB/waypoints/X1-AB-C1/market"))
+	assert.Zero(t, ttl("/systems/X1-AB/waypoints/X1-AB-C1/construction"))
+	assert.Zero(t, ttl("/my/ships"))
+	assert.Zero(t, ttl("/agents"))
+}
+
+// newResponseCacheServer serves every GET with the number of times the path was requested
+func newResponseCacheServer(t *testing.T) (*httptest.Server, func(path string) int) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+			return
+		}
+
+		mu.Lock()
+		requests[r.URL.RequestURI()]++
+		count := requests[r.URL.RequestURI()]
+		mu.Unlock()
+		fmt.Fprintf(w, `{"data":{"count":%d}}`, count)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[path]
+	}
+}
+
+type countResponse struct {
+	Data struct {
+		Count int `json:"count"`
+	} `json:"data"`
+}
+
+func TestResponseCache(t *testing.T) {
+	server, requests := newResponseCacheServer(t)
+
+	policy := DefaultCachePolicy()
+	policy.Rules = append([]CacheRule{{Pattern: "/systems/*/waypoints/*/market", TTL: 50 * time.Millisecond}}, policy.Rules...)
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.CachePolicy = &policy
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	get := func(endpoint string, queryParams map[string]string) int {
+		var response countResponse
+		require.Nil(t, c.Get(endpoint, queryParams, &response))
+		return response.Data.Count
+	}
+
+	// Cached until the reset
+	assert.Equal(t, 1, get("/systems/X1", nil))
+	assert.Equal(t, 1, get("/systems/X1", nil))
+	assert.Equal(t, 1, requests("/systems/X1"))
+
+	// Query parameters are part of the key, in any order
+	assert.Equal(t, 1, get("/systems", map[string]string{"page": "2", "limit": "20"}))
+	assert.Equal(t, 1, get("/systems", map[string]string{"limit": "20", "page": "2"}))
+	assert.Equal(t, 1, get("/systems", map[string]string{"page": "3", "limit": "20"}))
+
+	// Cached for the rule's TTL
+	market := "/systems/X1/waypoints/X1-A1/market"
+	assert.Equal(t, 1, get(market, nil))
+	assert.Equal(t, 1, get(market, nil))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, 2, get(market, nil))
+
+	// Endpoints matching no rule are never cached
+	assert.Equal(t, 1, get("/agents", nil))
+	assert.Equal(t, 2, get("/agents", nil))
+}
+
+func TestResponseCacheDisabled(t *testing.T) {
+	server, requests := newResponseCacheServer(t)
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.CachePolicy = &CachePolicy{}
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	require.Nil(t, c.Get("/systems/X1", nil, nil))
+	require.Nil(t, c.Get("/systems/X1", nil, nil))
+	assert.Equal(t, 2, requests("/systems/X1"))
+}
+
+func TestResponseCachePersistsUntilReset(t *testing.T) {
+	server, requests := newResponseCacheServer(t)
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.CacheDir = t.TempDir()
+
+	first, err := NewClient(options)
+	require.NoError(t, err)
+	defer first.Close(t.Context())
+	require.Nil(t, first.Get("/systems/X1/waypoints", map[string]string{"page": "1"}, nil))
+
+	// A restarted client reads the response from CacheDir
+	restarted, err := NewClient(options)
+	require.NoError(t, err)
+	defer restarted.Close(t.Context())
+
+	var response countResponse
+	require.Nil(t, restarted.Get("/systems/X1/waypoints", map[string]string{"page": "1"}, &response))
+	assert.Equal(t, 1, response.Data.Count)
+	assert.Equal(t, 1, requests("/systems/X1/waypoints?page=1"))
+}
+
+func TestResponseCacheSnapshot(t *testing.T) {
+	server, requests := newResponseCacheServer(t)
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.Cache = cache.NewMemory(cache.MemoryOptions{})
+
+	first, err := NewClient(options)
+	require.NoError(t, err)
+	defer first.Close(t.Context())
+	assert.Same(t, options.Cache, first.CacheClient)
+
+	require.Nil(t, first.Get("/systems/X1", nil, nil))
+	responses := first.CacheClient.Namespace(ResponseNamespace)
+	require.Len(t, responses.Entries(nil), 1)
+	assert.Equal(t, "GET /systems/X1", responses.Entries(nil)[0].Key)
+
+	var snapshot bytes.Buffer
+	require.NoError(t, first.CacheClient.Dump(&snapshot))
+
+	// A client restored from the snapshot answers from the cache
+	options.Cache = nil
+	restored, err := NewClient(options)
+	require.NoError(t, err)
+	defer restored.Close(t.Context())
+	require.NoError(t, restored.CacheClient.Restore(&snapshot))
+
+	var response countResponse
+	require.Nil(t, restored.Get("/systems/X1", nil, &response))
+	assert.Equal(t, 1, response.Data.Count)
+	assert.Equal(t, 1, requests("/systems/X1"))
+}
+
+func TestResponseCacheObservedInDryRun(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `{"data":{"status":"ok","resetDate":"2026-10-18"}}`)
+			return
+		}
+		requests.Add(1)
+		fmt.Fprint(w, `{"data":{"symbol":"X1-A1","systemSymbol":"X1","x":3,"y":4}}`)
+	}))
+	defer server.Close()
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.CacheDir = t.TempDir()
+
+	first, err := NewClient(options)
+	require.NoError(t, err)
+	defer first.Close(t.Context())
+	require.Nil(t, first.Get("/systems/X1/waypoints/X1-A1", nil, nil))
+
+	// A restarted dry-run client observes the waypoint read from CacheDir
+	options.DryRun = true
+	restarted, err := NewClient(options)
+	require.NoError(t, err)
+	defer restarted.Close(t.Context())
+	require.Nil(t, restarted.Get("/systems/X1/waypoints/X1-A1", nil, nil))
+	assert.Equal(t, int32(1), requests.Load())
+
+	restarted.dryRun.mu.Lock()
+	defer restarted.dryRun.mu.Unlock()
+	assert.Contains(t, restarted.dryRun.waypoints, "X1-A1")
+}
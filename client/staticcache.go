@@ -0,0 +1,86 @@
+package client
+
+import (
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/internal/cache"
+)
+
+// LoadStatic decodes the static universe data stored under key in ClientOptions.CacheDir
+// into value, reporting whether it was found. Systems, waypoints and jump gates do not
+// change within a reset, so they are kept on disk across restarts. The disk store sits
+// beside CacheClient rather than behind it: only callers that use LoadStatic and SaveStatic,
+// the response cache for CacheUntilReset rules and pathfinding graphs, persist their data.
+// Nothing is found without a CacheDir, or when the data was stored before the current reset.
+func (c *Client) LoadStatic(key string, value any) bool {
+	store := c.staticStore()
+	if store == nil {
+		return false
+	}
+
+	found, err := store.Load(key, value)
+	if err != nil {
+		c.Logger.Warn("Failed to load static data", "key", key, "error", err)
+		return false
+	}
+	return found
+}
+
+// SaveStatic stores static universe data under key in ClientOptions.CacheDir until the
+// next reset. Nothing is stored without a CacheDir.
+func (c *Client) SaveStatic(key string, value any) {
+	store := c.staticStore()
+	if store == nil {
+		return
+	}
+
+	if err := store.Save(key, value); err != nil {
+		c.Logger.Warn("Failed to save static data", "key", key, "error", err)
+	}
+}
+
+// staticStore returns the on-disk store for static universe data, or nil if
+// ClientOptions.CacheDir is unset or the server's reset date cannot be fetched. The store
+// is namespaced by reset date, resolved on first use, and data from other resets is deleted.
+// After a failed fetch the store stays unavailable for staticRetryInterval, so that callers
+// such as cache invalidation do not send a status request each.
+func (c *Client) staticStore() *cache.DiskStore {
+	if c.staticCache == nil {
+		return nil
+	}
+
+	c.staticMu.Lock()
+	defer c.staticMu.Unlock()
+
+	if c.staticCache.Namespace() == "" {
+		if !c.staticFailedAt.IsZero() && time.Since(c.staticFailedAt) < staticRetryInterval {
+			return nil
+		}
+		resetDate := c.currentResetDate()
+		if resetDate == "" {
+			c.staticFailedAt = time.Now()
+			return nil
+		}
+		c.staticFailedAt = time.Time{}
+		if err := c.staticCache.SetNamespace(resetDate); err != nil {
+			c.Logger.Warn("Failed to delete static data from previous resets", "error", err)
+		}
+	}
+	return c.staticCache
+}
+
+// resetStaticStore switches the on-disk store to resetDate after a game reset, deleting the
+// data from before it. An empty resetDate resolves the reset date again on next use.
+func (c *Client) resetStaticStore(resetDate string) {
+	if c.staticCache == nil {
+		return
+	}
+
+	c.staticMu.Lock()
+	defer c.staticMu.Unlock()
+
+	c.staticFailedAt = time.Time{}
+	if err := c.staticCache.SetNamespace(resetDate); err != nil {
+		c.Logger.Warn("Failed to delete static data from previous resets", "error", err)
+	}
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticCacheSurvivesRestartsWithinReset(t *testing.T) {
+	var resetDate atomic.Value
+	resetDate.Store("2026-10-11")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"status":"ok","resetDate":"%s"}}`, resetDate.Load())
+	}))
+	defer server.Close()
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.CacheDir = t.TempDir()
+
+	newTestClient := func() *Client {
+		c, err := NewClient(options)
+		require.NoError(t, err)
+		t.Cleanup(func() { c.Close(t.Context()) })
+		return c
+	}
+
+	type system struct{ Symbol string }
+
+	first := newTestClient()
+	var loaded system
+	assert.False(t, first.LoadStatic("system_X1", &loaded))
+	first.SaveStatic("system_X1", system{Symbol: "X1"})
+
+	// A restarted client within the same reset reads the saved data
+	restarted := newTestClient()
+	require.True(t, restarted.LoadStatic("system_X1", &loaded))
+	assert.Equal(t, "X1", loaded.Symbol)
+
+	// A client started after a reset drops it
+	resetDate.Store("2026-10-18")
+	afterReset := newTestClient()
+	assert.False(t, afterReset.LoadStatic("system_X1", &loaded))
+
+	// So does a running client once it detects the reset
+	restarted.resetStaticStore("")
+	assert.False(t, restarted.LoadStatic("system_X1", &loaded))
+}
+
+func TestStaticCacheDisabledWithoutCacheDir(t *testing.T) {
+	options := DefaultClientOptions()
+	options.BaseURL = "http://127.0.0.1:1"
+	options.AuthMode = AuthAnonymous
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	c.SaveStatic("system_X1", "X1")
+	var loaded string
+	assert.False(t, c.LoadStatic("system_X1", &loaded))
+}
+
+func TestStaticCacheBacksOffWhenResetDateUnavailable(t *testing.T) {
+	var statusRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			statusRequests.Add(1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"error":{"code":503,"message":"maintenance"}}`)
+	}))
+	defer server.Close()
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.CacheDir = t.TempDir()
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(t.Context())
+
+	var loaded string
+	for range 5 {
+		assert.False(t, c.LoadStatic("system_X1", &loaded))
+		c.SaveStatic("system_X1", "X1")
+	}
+	assert.Equal(t, int32(1), statusRequests.Load(), "the reset date is not fetched again until the retry interval passes")
+
+	// A detected reset resolves the reset date again straight away
+	c.resetStaticStore("")
+	assert.False(t, c.LoadStatic("system_X1", &loaded))
+	assert.Equal(t, int32(2), statusRequests.Load())
+}
@@ -0,0 +1,479 @@
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccountTokenKey is the key under which the account token used to register agents is stored
+const AccountTokenKey = "account"
+
+// DefaultTokenFile is the token file used when no TokenStore is configured
+const DefaultTokenFile = "tokens.json"
+
+// TokenEntry is a stored token and the server reset it was issued for
+type TokenEntry struct {
+	Token string
+	// ResetDate is the server's resetDate when the token was stored, empty if unknown
+	ResetDate string
+}
+
+// TokenStore persists agent and account tokens by symbol.
+// The account token is stored under AccountTokenKey.
+type TokenStore interface {
+	// Get returns the entry for symbol and whether it exists
+	Get(symbol string) (TokenEntry, bool, error)
+	// Set stores the entry for symbol, replacing any existing entry
+	Set(symbol string, entry TokenEntry) error
+	// Delete removes the entry for symbol, if any
+	Delete(symbol string) error
+	// List returns every stored entry keyed by symbol
+	List() (map[string]TokenEntry, error)
+}
+
+// TokenFile represents the structure of the token file
+type TokenFile struct {
+	Tokens map[string]string `json:"tokens"`
+	// Resets records the server reset each token was issued for, keyed by symbol
+	Resets map[string]string `json:"resets,omitempty"`
+}
+
+// encryptedTokenFile is the on-disk form of an encrypted token file
+type encryptedTokenFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+const (
+	lockRetryInterval = 10 * time.Millisecond
+	lockTimeout       = 5 * time.Second
+	// A lock file older than this is assumed to be left behind by a crashed process
+	staleLockAge = 30 * time.Second
+
+	keyDerivationIterations = 600_000
+	keyLength               = 32
+	saltLength              = 16
+)
+
+// FileTokenStore stores tokens in a JSON file, optionally encrypted at rest.
+// Writes are atomic and guarded by a lock file, so several processes can share one file.
+type FileTokenStore struct {
+	mu         sync.Mutex
+	path       string
+	passphrase string
+	// Derived keys are cached by salt as key derivation is deliberately slow
+	keys map[string][]byte
+}
+
+// NewFileTokenStore creates a TokenStore backed by the JSON file at path.
+// The file is created with mode 0600 on the first write.
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// NewEncryptedFileTokenStore creates a TokenStore backed by a file at path that is
+// encrypted with AES-GCM under a key derived from passphrase
+func NewEncryptedFileTokenStore(path, passphrase string) (*FileTokenStore, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase is required for an encrypted token store")
+	}
+
+	return &FileTokenStore{
+		path:       path,
+		passphrase: passphrase,
+		keys:       make(map[string][]byte),
+	}, nil
+}
+
+// Get returns the entry for symbol
+func (s *FileTokenStore) Get(symbol string) (TokenEntry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokenFile, err := s.read()
+	if err != nil {
+		return TokenEntry{}, false, err
+	}
+
+	token, exists := tokenFile.Tokens[symbol]
+	if !exists {
+		return TokenEntry{}, false, nil
+	}
+	return TokenEntry{Token: token, ResetDate: tokenFile.Resets[symbol]}, true, nil
+}
+
+// Set stores the entry for symbol
+func (s *FileTokenStore) Set(symbol string, entry TokenEntry) error {
+	return s.update(func(tokenFile *TokenFile) {
+		tokenFile.Tokens[symbol] = entry.Token
+		if entry.ResetDate != "" {
+			tokenFile.Resets[symbol] = entry.ResetDate
+		} else {
+			delete(tokenFile.Resets, symbol)
+		}
+	})
+}
+
+// Delete removes the entry for symbol
+func (s *FileTokenStore) Delete(symbol string) error {
+	return s.update(func(tokenFile *TokenFile) {
+		delete(tokenFile.Tokens, symbol)
+		delete(tokenFile.Resets, symbol)
+	})
+}
+
+// List returns every stored entry
+func (s *FileTokenStore) List() (map[string]TokenEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokenFile, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]TokenEntry, len(tokenFile.Tokens))
+	for symbol, token := range tokenFile.Tokens {
+		entries[symbol] = TokenEntry{Token: token, ResetDate: tokenFile.Resets[symbol]}
+	}
+	return entries, nil
+}
+
+// update applies fn to the token file under the lock file and writes the result atomically
+func (s *FileTokenStore) update(fn func(tokenFile *TokenFile)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokenFile, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	fn(&tokenFile)
+	return s.write(tokenFile)
+}
+
+// lock acquires the lock file next to the token file, waiting for other writers
+func (s *FileTokenStore) lock() (func(), error) {
+	lockPath := s.path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to lock token file: %w", err)
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for token file lock %s", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// read loads the token file, returning an empty file if it does not exist yet
+func (s *FileTokenStore) read() (TokenFile, error) {
+	tokenFile := TokenFile{
+		Tokens: make(map[string]string),
+		Resets: make(map[string]string),
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return tokenFile, nil
+		}
+		return tokenFile, fmt.Errorf("failed to read token file: %w", err)
+	}
+
+	if s.passphrase != "" {
+		data, err = s.decrypt(data)
+		if err != nil {
+			return tokenFile, err
+		}
+	}
+
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return tokenFile, nil
+	}
+	if err := json.Unmarshal(data, &tokenFile); err != nil {
+		return tokenFile, fmt.Errorf("failed to decode token file: %w", err)
+	}
+
+	if tokenFile.Tokens == nil {
+		tokenFile.Tokens = make(map[string]string)
+	}
+	if tokenFile.Resets == nil {
+		tokenFile.Resets = make(map[string]string)
+	}
+	return tokenFile, nil
+}
+
+// write replaces the token file with tokenFile via a temporary file and rename
+func (s *FileTokenStore) write(tokenFile TokenFile) error {
+	data, err := json.MarshalIndent(tokenFile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if s.passphrase != "" {
+		data, err = s.encrypt(data)
+		if err != nil {
+			return err
+		}
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary token file: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	if err := temp.Chmod(0600); err != nil {
+		temp.Close()
+		return err
+	}
+	if _, err := temp.Write(append(data, '\n')); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write token file: %w", err)
+	}
+	if err := temp.Sync(); err != nil {
+		temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(temp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace token file: %w", err)
+	}
+	return nil
+}
+
+// key derives the encryption key for salt
+func (s *FileTokenStore) key(salt []byte) ([]byte, error) {
+	if key, ok := s.keys[string(salt)]; ok {
+		return key, nil
+	}
+
+	key, err := pbkdf2.Key(sha256.New, s.passphrase, salt, keyDerivationIterations, keyLength)
+	if err != nil {
+		return nil, err
+	}
+	s.keys[string(salt)] = key
+	return key, nil
+}
+
+// encrypt seals data under a fresh salt and nonce
+func (s *FileTokenStore) encrypt(data []byte) ([]byte, error) {
+	salt := make([]byte, saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := s.cipher(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(encryptedTokenFile{
+		Salt:  salt,
+		Nonce: nonce,
+		Data:  gcm.Seal(nil, nonce, data, nil),
+	})
+}
+
+// decrypt opens an encrypted token file
+func (s *FileTokenStore) decrypt(data []byte) ([]byte, error) {
+	var encrypted encryptedTokenFile
+	if err := json.Unmarshal(data, &encrypted); err != nil {
+		return nil, fmt.Errorf("failed to decode encrypted token file: %w", err)
+	}
+
+	gcm, err := s.cipher(encrypted.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := gcm.Open(nil, encrypted.Nonce, encrypted.Data, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt token file: wrong passphrase or corrupted file")
+	}
+	return plain, nil
+}
+
+// cipher returns the AES-GCM cipher for salt
+func (s *FileTokenStore) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := s.key(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EnvTokenStore reads tokens from environment variables: SPACETRADERS_ACCOUNT_TOKEN for
+// the account token and SPACETRADERS_TOKEN_<SYMBOL> for agents, with the symbol upper-cased
+// and '-' replaced by '_'. Reset dates are read from SPACETRADERS_RESET_DATE_<SYMBOL>.
+// Set and Delete only change the environment of the current process.
+type EnvTokenStore struct{}
+
+// NewEnvTokenStore creates a TokenStore backed by environment variables
+func NewEnvTokenStore() *EnvTokenStore {
+	return &EnvTokenStore{}
+}
+
+const (
+	envAccountToken    = "SPACETRADERS_ACCOUNT_TOKEN"
+	envTokenPrefix     = "SPACETRADERS_TOKEN_"
+	envResetDatePrefix = "SPACETRADERS_RESET_DATE_"
+)
+
+// envSuffix converts a symbol into the suffix used in variable names
+func envSuffix(symbol string) string {
+	return strings.ToUpper(strings.ReplaceAll(symbol, "-", "_"))
+}
+
+// envTokenVariable returns the variable holding the token for symbol
+func envTokenVariable(symbol string) string {
+	if symbol == AccountTokenKey {
+		return envAccountToken
+	}
+	return envTokenPrefix + envSuffix(symbol)
+}
+
+// Get returns the entry for symbol from the environment
+func (s *EnvTokenStore) Get(symbol string) (TokenEntry, bool, error) {
+	token, ok := os.LookupEnv(envTokenVariable(symbol))
+	if !ok || token == "" {
+		return TokenEntry{}, false, nil
+	}
+	return TokenEntry{Token: token, ResetDate: os.Getenv(envResetDatePrefix + envSuffix(symbol))}, true, nil
+}
+
+// Set stores the entry in the environment of the current process
+func (s *EnvTokenStore) Set(symbol string, entry TokenEntry) error {
+	if err := os.Setenv(envTokenVariable(symbol), entry.Token); err != nil {
+		return err
+	}
+	if entry.ResetDate == "" {
+		return os.Unsetenv(envResetDatePrefix + envSuffix(symbol))
+	}
+	return os.Setenv(envResetDatePrefix+envSuffix(symbol), entry.ResetDate)
+}
+
+// Delete removes the entry from the environment of the current process
+func (s *EnvTokenStore) Delete(symbol string) error {
+	if err := os.Unsetenv(envTokenVariable(symbol)); err != nil {
+		return err
+	}
+	return os.Unsetenv(envResetDatePrefix + envSuffix(symbol))
+}
+
+// List returns every token found in the environment. Agent symbols are returned in
+// their variable form, as the original symbol cannot be recovered from it.
+func (s *EnvTokenStore) List() (map[string]TokenEntry, error) {
+	entries := make(map[string]TokenEntry)
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if value == "" {
+			continue
+		}
+
+		switch {
+		case name == envAccountToken:
+			entries[AccountTokenKey] = TokenEntry{Token: value, ResetDate: os.Getenv(envResetDatePrefix + envSuffix(AccountTokenKey))}
+		case strings.HasPrefix(name, envTokenPrefix):
+			symbol := strings.TrimPrefix(name, envTokenPrefix)
+			entries[symbol] = TokenEntry{Token: value, ResetDate: os.Getenv(envResetDatePrefix + symbol)}
+		}
+	}
+	return entries, nil
+}
+
+// MemoryTokenStore keeps tokens in memory for the lifetime of the process
+type MemoryTokenStore struct {
+	mu      sync.RWMutex
+	entries map[string]TokenEntry
+}
+
+// NewMemoryTokenStore creates an empty in-memory TokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{entries: make(map[string]TokenEntry)}
+}
+
+// Get returns the entry for symbol
+func (s *MemoryTokenStore) Get(symbol string) (TokenEntry, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entry, ok := s.entries[symbol]
+	return entry, ok, nil
+}
+
+// Set stores the entry for symbol
+func (s *MemoryTokenStore) Set(symbol string, entry TokenEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[symbol] = entry
+	return nil
+}
+
+// Delete removes the entry for symbol
+func (s *MemoryTokenStore) Delete(symbol string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, symbol)
+	return nil
+}
+
+// List returns a copy of every stored entry
+func (s *MemoryTokenStore) List() (map[string]TokenEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	entries := make(map[string]TokenEntry, len(s.entries))
+	for symbol, entry := range s.entries {
+		entries[symbol] = entry
+	}
+	return entries, nil
+}
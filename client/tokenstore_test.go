@@ -0,0 +1,112 @@
+package client
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testTokenStore(t *testing.T, store TokenStore) {
+	_, exists, err := store.Get("TEST")
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "agent-token", ResetDate: "2026-10-11"}))
+	require.NoError(t, store.Set(AccountTokenKey, TokenEntry{Token: "account-token"}))
+
+	entry, exists, err := store.Get("TEST")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, TokenEntry{Token: "agent-token", ResetDate: "2026-10-11"}, entry)
+
+	entries, err := store.List()
+	require.NoError(t, err)
+	assert.Equal(t, "account-token", entries[AccountTokenKey].Token)
+
+	require.NoError(t, store.Delete("TEST"))
+	_, exists, err = store.Get("TEST")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, NewMemoryTokenStore())
+}
+
+func TestEnvTokenStore(t *testing.T) {
+	t.Setenv(envAccountToken, "")
+	t.Setenv(envTokenPrefix+"TEST", "")
+	t.Setenv(envResetDatePrefix+"TEST", "")
+	testTokenStore(t, NewEnvTokenStore())
+
+	t.Setenv(envTokenPrefix+"MY_AGENT", "from-env")
+	entry, exists, err := NewEnvTokenStore().Get("my-agent")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "from-env", entry.Token)
+}
+
+func TestFileTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	testTokenStore(t, NewFileTokenStore(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = os.Stat(path + ".lock")
+	assert.True(t, os.IsNotExist(err), "lock file should be removed after writing")
+}
+
+func TestFileTokenStore_ReadsLegacyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"tokens":{"account":"account-token","TEST":"agent-token"}}`), 0644))
+
+	store := NewFileTokenStore(path)
+	entry, exists, err := store.Get("TEST")
+	require.NoError(t, err)
+	assert.True(t, exists)
+	assert.Equal(t, "agent-token", entry.Token)
+	assert.Empty(t, entry.ResetDate)
+
+	require.NoError(t, store.Set("OTHER", TokenEntry{Token: "other-token", ResetDate: "2026-10-11"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"account": "account-token"`)
+	assert.Contains(t, string(data), `"resets"`)
+}
+
+func TestEncryptedFileTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.enc")
+
+	store, err := NewEncryptedFileTokenStore(path, "correct horse")
+	require.NoError(t, err)
+	testTokenStore(t, store)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "account-token"), "tokens must not be stored in plain text")
+
+	wrong, err := NewEncryptedFileTokenStore(path, "wrong passphrase")
+	require.NoError(t, err)
+	_, _, err = wrong.Get(AccountTokenKey)
+	assert.Error(t, err)
+
+	_, err = NewEncryptedFileTokenStore(path, "")
+	assert.Error(t, err)
+}
+
+func TestGetOrRegisterToken_StoredToken(t *testing.T) {
+	store := NewMemoryTokenStore()
+	require.NoError(t, store.Set("TEST", TokenEntry{Token: "agent-token"}))
+
+	c := &Client{Logger: slog.Default(), tokenStore: store}
+	require.NoError(t, c.getOrRegisterToken("COSMIC", "TEST", ""))
+	assert.Equal(t, "agent-token", c.GetToken())
+}
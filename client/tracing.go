@@ -0,0 +1,67 @@
+package client
+
+import (
+	"context"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "spacetraders-client"
+
+// Span names used for API requests
+const (
+	spanRequest   = "spacetraders.request"
+	spanQueueWait = "spacetraders.queue_wait"
+	spanHTTP      = "spacetraders.http"
+)
+
+// tracer returns the tracer for request spans. It is looked up from the global provider
+// on each use so spans follow whichever provider the application has installed.
+func tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// startRequestSpan starts the span covering a request from enqueue until its response is returned.
+// It becomes a child of any span already in ctx.
+func startRequestSpan(ctx context.Context, method, endpoint string, redactor *Redactor) (context.Context, trace.Span) {
+	return tracer().Start(ctx, spanRequest,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("http.request.method", method),
+			attribute.String("spacetraders.endpoint", redactor.String(endpoint)),
+		),
+	)
+}
+
+// recordQueueWait records the time a request spent waiting in the queue as a child span
+func recordQueueWait(req apiRequest, redactor *Redactor) {
+	_, span := tracer().Start(req.ctx, spanQueueWait,
+		trace.WithTimestamp(req.enqueuedAt),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.method),
+			attribute.String("spacetraders.endpoint", redactor.String(req.endpoint)),
+		),
+	)
+	span.End(trace.WithTimestamp(req.startedAt))
+}
+
+// endSpanWithError marks a span as failed if the request returned an API error
+func endSpanWithError(span trace.Span, apiErr *models.APIError, redactor *Redactor) {
+	if apiErr != nil {
+		span.SetAttributes(attribute.Int("spacetraders.error_code", apiErr.Code))
+		span.SetStatus(codes.Error, redactor.String(apiErr.Message))
+	}
+	span.End()
+}
+
+// redactor returns the redactor of the client executing the queue's requests, if any
+func (q *RequestQueue) redactor() *Redactor {
+	if client, ok := q.executor.(*Client); ok {
+		return client.redactor
+	}
+	return nil
+}
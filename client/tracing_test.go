@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestRequestQueue_Spans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	mockExec := &mockExecutor{
+		executeRequestFunc: func(ctx context.Context, method, endpoint string, body interface{}, queryParams map[string]string, result interface{}) *models.APIError {
+			return nil
+		},
+	}
+
+	queue := NewRequestQueue(context.Background(), mockExec, 10)
+	defer queue.Shutdown()
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "bot.action")
+	err := queue.EnqueueWithContext(ctx, "GET", "/my/agent", nil, nil, nil)
+	parent.End()
+	assert.Nil(t, err)
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	request, ok := spans[spanRequest]
+	if assert.True(t, ok, "request span should be recorded") {
+		assert.Equal(t, parent.SpanContext().SpanID(), request.Parent().SpanID())
+		assert.Equal(t, parent.SpanContext().TraceID(), request.SpanContext().TraceID())
+	}
+
+	wait, ok := spans[spanQueueWait]
+	if assert.True(t, ok, "queue wait span should be recorded") {
+		assert.Equal(t, request.SpanContext().SpanID(), wait.Parent().SpanID())
+	}
+}
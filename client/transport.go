@@ -0,0 +1,191 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// DefaultUserAgent is sent with every request unless TransportOptions.UserAgent is set
+const DefaultUserAgent = "spacetraders-client-go (+https://github.com/jjkirkpatrick/spacetraders-client)"
+
+// TransportOptions tunes the HTTP transport used to reach the API
+type TransportOptions struct {
+	// RequestTimeout limits a single HTTP attempt, from dialling to reading the body
+	// (default: 30s). A hung connection fails the attempt instead of blocking the queue.
+	RequestTimeout time.Duration
+	// Timeout limits a whole request, including time spent queued and retrying
+	// (default: 0, no limit). Contexts passed to the *WithContext methods apply as well.
+	Timeout time.Duration
+	// SlowRequestThreshold is how long an attempt may take before it is logged and counted
+	// in api_slow_requests_total (default: 5s, negative disables)
+	SlowRequestThreshold time.Duration
+	// ProxyURL sends requests through a proxy, e.g. "http://proxy:3128".
+	// Empty uses the HTTP_PROXY and HTTPS_PROXY environment variables.
+	ProxyURL string
+	// TLSConfig overrides the TLS settings, e.g. to trust a private CA (optional)
+	TLSConfig *tls.Config
+	// DialTimeout limits establishing a TCP connection (default: 10s)
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout limits the TLS handshake (default: 10s)
+	TLSHandshakeTimeout time.Duration
+	// KeepAlive is the interval between TCP keep-alive probes (default: 30s)
+	KeepAlive time.Duration
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool
+	// IdleConnTimeout is how long an idle connection stays in the pool (default: 90s)
+	IdleConnTimeout time.Duration
+	// MaxIdleConns limits idle connections across all hosts (default: 10)
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits idle connections to the API host (default: 4)
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits all connections to the API host (default: 0, no limit)
+	MaxConnsPerHost int
+	// UserAgent is sent with every request (default: DefaultUserAgent)
+	UserAgent string
+}
+
+// DefaultTransportOptions returns the default configuration for the HTTP transport
+func DefaultTransportOptions() *TransportOptions {
+	return &TransportOptions{
+		RequestTimeout:       30 * time.Second,
+		SlowRequestThreshold: 5 * time.Second,
+		DialTimeout:          10 * time.Second,
+		TLSHandshakeTimeout:  10 * time.Second,
+		KeepAlive:            30 * time.Second,
+		IdleConnTimeout:      90 * time.Second,
+		MaxIdleConns:         10,
+		MaxIdleConnsPerHost:  4,
+		UserAgent:            DefaultUserAgent,
+	}
+}
+
+// withDefaults returns a copy of the options with unset values replaced by their defaults
+func (o *TransportOptions) withDefaults() TransportOptions {
+	defaults := DefaultTransportOptions()
+	if o == nil {
+		return *defaults
+	}
+
+	options := *o
+	if options.RequestTimeout == 0 {
+		options.RequestTimeout = defaults.RequestTimeout
+	}
+	if options.SlowRequestThreshold == 0 {
+		options.SlowRequestThreshold = defaults.SlowRequestThreshold
+	}
+	if options.DialTimeout == 0 {
+		options.DialTimeout = defaults.DialTimeout
+	}
+	if options.TLSHandshakeTimeout == 0 {
+		options.TLSHandshakeTimeout = defaults.TLSHandshakeTimeout
+	}
+	if options.KeepAlive == 0 {
+		options.KeepAlive = defaults.KeepAlive
+	}
+	if options.IdleConnTimeout == 0 {
+		options.IdleConnTimeout = defaults.IdleConnTimeout
+	}
+	if options.MaxIdleConns == 0 {
+		options.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if options.MaxIdleConnsPerHost == 0 {
+		options.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+	if options.UserAgent == "" {
+		options.UserAgent = defaults.UserAgent
+	}
+	return options
+}
+
+// newHTTPClient creates the resty client for the transport options
+func newHTTPClient(options TransportOptions) (*resty.Client, error) {
+	proxy := http.ProxyFromEnvironment
+	if options.ProxyURL != "" {
+		proxyURL, err := url.Parse(options.ProxyURL)
+		if err != nil || proxyURL.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", options.ProxyURL)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   options.DialTimeout,
+		KeepAlive: options.KeepAlive,
+	}
+
+	transport := &http.Transport{
+		Proxy:               proxy,
+		DialContext:         dialer.DialContext,
+		TLSClientConfig:     options.TLSConfig,
+		TLSHandshakeTimeout: options.TLSHandshakeTimeout,
+		DisableKeepAlives:   options.DisableKeepAlives,
+		IdleConnTimeout:     options.IdleConnTimeout,
+		MaxIdleConns:        options.MaxIdleConns,
+		MaxIdleConnsPerHost: options.MaxIdleConnsPerHost,
+		MaxConnsPerHost:     options.MaxConnsPerHost,
+		ForceAttemptHTTP2:   true,
+	}
+
+	return resty.New().
+		SetTransport(transport).
+		SetTimeout(options.RequestTimeout).
+		SetHeader("User-Agent", options.UserAgent), nil
+}
+
+// isTimeout reports whether err is a timeout rather than another transport failure
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// contextError converts the error of a finished request context into an API error
+func contextError(ctx context.Context) *models.APIError {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &models.APIError{
+			Code:    http.StatusRequestTimeout,
+			Message: "request timed out: " + ctx.Err().Error(),
+		}
+	}
+	return &models.APIError{
+		Code:    499, // Client closed request
+		Message: "request cancelled: " + ctx.Err().Error(),
+	}
+}
+
+// recordSlowRequest logs and counts an attempt that took longer than the slow request threshold
+func (c *Client) recordSlowRequest(ctx context.Context, method, endpoint string, duration time.Duration) {
+	if c.slowThreshold < 0 || duration < c.slowThreshold {
+		return
+	}
+
+	c.Logger.Warn("Slow API request",
+		"method", method,
+		"endpoint", endpoint,
+		"duration", duration.Round(time.Millisecond),
+		"threshold", c.slowThreshold)
+
+	if c.meter == nil {
+		return // Telemetry is disabled
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("agent", c.AgentSymbol),
+		attribute.String("endpoint", endpoint),
+		attribute.String("method", method),
+	}
+	for key, value := range GetMetricLabels(ctx) {
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	c.slowCounter.Add(c.context, 1, metric.WithAttributes(attrs...))
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newTransportClient(t *testing.T, baseURL string, transport *TransportOptions) *Client {
+	options := DefaultClientOptions()
+	options.BaseURL = baseURL
+	options.AuthMode = AuthAnonymous
+	options.Transport = transport
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close(context.Background()) })
+	return c
+}
+
+func TestRequestTimeoutDoesNotBlockQueue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/hang" {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	c := newTransportClient(t, server.URL, &TransportOptions{RequestTimeout: 100 * time.Millisecond})
+
+	var result map[string]interface{}
+	err := c.Get("/hang", nil, &result)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusRequestTimeout, err.Code)
+
+	assert.Nil(t, c.Get("/status", nil, &result), "the queue keeps working after a hung request")
+}
+
+func TestTimeoutCoversQueueAndRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	c := newTransportClient(t, server.URL, &TransportOptions{Timeout: 200 * time.Millisecond})
+
+	start := time.Now()
+	err := c.Get("/hang", nil, nil)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusRequestTimeout, err.Code)
+	assert.Contains(t, err.Message, "timed out")
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = c.GetWithContext(ctx, "/hang", nil, nil)
+	require.NotNil(t, err)
+	assert.Equal(t, 499, err.Code)
+}
+
+func TestTransportProxyAndUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	var host, userAgent string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		host, userAgent = r.URL.Host, r.Header.Get("User-Agent")
+		mu.Unlock()
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer proxy.Close()
+
+	c := newTransportClient(t, "http://api.spacetraders.invalid/v2", &TransportOptions{
+		ProxyURL:  proxy.URL,
+		UserAgent: "fleet-bot/1.0",
+	})
+
+	require.Nil(t, c.Get("/status", nil, nil))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, "api.spacetraders.invalid", host)
+	assert.Equal(t, "fleet-bot/1.0", userAgent)
+
+	options := DefaultClientOptions()
+	options.AuthMode = AuthAnonymous
+	options.Transport = &TransportOptions{ProxyURL: "not a proxy"}
+	_, err := NewClient(options)
+	assert.ErrorContains(t, err, "invalid proxy URL")
+}
+
+func TestSlowRequestMetric(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	options := DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.AuthMode = AuthAnonymous
+	options.Transport = &TransportOptions{SlowRequestThreshold: 50 * time.Millisecond}
+	options.TelemetryOptions = &TelemetryOptions{
+		ServiceName:    "test",
+		MetricExporter: telemetry.ExporterMemory,
+		EnableMetrics:  true,
+	}
+
+	c, err := NewClient(options)
+	require.NoError(t, err)
+	defer c.Close(context.Background())
+
+	require.Nil(t, c.Get("/fast", nil, nil))
+	require.Nil(t, c.Get("/slow", nil, nil))
+
+	slow, ok, err := c.InMemoryTelemetry().Metric(context.Background(), "api_slow_requests_total")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	points := slow.Data.(metricdata.Sum[int64]).DataPoints
+	require.Len(t, points, 1)
+	endpoint, _ := points[0].Attributes.Value("endpoint")
+	assert.Equal(t, "/slow", endpoint.AsString())
+	assert.Equal(t, int64(1), points[0].Value)
+}
+
+func TestRateLimitWaitFollowsRequestContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	c := newTransportClient(t, server.URL, &TransportOptions{Timeout: 200 * time.Millisecond})
+
+	// No requests remain until long after the request times out
+	c.RateLimiter.mu.Lock()
+	c.RateLimiter.remaining = 0
+	c.RateLimiter.resetTime = time.Now().Add(time.Hour)
+	c.RateLimiter.mu.Unlock()
+
+	start := time.Now()
+	err := c.Get("/status", nil, nil)
+	require.NotNil(t, err)
+	assert.Equal(t, http.StatusRequestTimeout, err.Code)
+	assert.Less(t, time.Since(start), 5*time.Second, "the request stops waiting for the rate limiter when it times out")
+}
@@ -1,84 +1,162 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+)
 
-	"github.com/phuslu/log"
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+	colorGray    = "\x1b[90m"
 )
 
-func Logformat(out io.Writer, args *log.FormatterArgs) (n int, err error) {
-
-	const (
-		Reset   = "\x1b[0m"
-		Black   = "\x1b[30m"
-		Red     = "\x1b[31m"
-		Green   = "\x1b[32m"
-		Yellow  = "\x1b[33m"
-		Blue    = "\x1b[34m"
-		Magenta = "\x1b[35m"
-		Cyan    = "\x1b[36m"
-		White   = "\x1b[37m"
-		Gray    = "\x1b[90m"
-	)
+// PrettyHandler is an slog.Handler that writes colourful, human-readable log lines
+// for the console. Pass it as ClientOptions.Handler to use it for the client's logs.
+type PrettyHandler struct {
+	mu     *sync.Mutex
+	out    io.Writer
+	level  slog.Leveler
+	attrs  []slog.Attr
+	groups []string
+}
+
+// NewPrettyHandler creates a PrettyHandler writing to out. If opts is nil, records
+// at slog.LevelInfo and above are written.
+func NewPrettyHandler(out io.Writer, opts *slog.HandlerOptions) *PrettyHandler {
+	var level slog.Leveler = slog.LevelInfo
+	if opts != nil && opts.Level != nil {
+		level = opts.Level
+	}
+
+	return &PrettyHandler{
+		mu:    &sync.Mutex{},
+		out:   out,
+		level: level,
+	}
+}
+
+// Enabled reports whether the handler writes records at the given level
+func (h *PrettyHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
 
+// Handle writes a record as a single line
+func (h *PrettyHandler) Handle(ctx context.Context, r slog.Record) error {
 	// colorful level string
-	var color, three string
-	switch args.Level {
-	case "trace":
-		color, three = Magenta, "TRACE"
-	case "debug":
-		color, three = Yellow, "DEBUG"
-	case "info":
-		color, three = Green, "INFO"
-	case "warn":
-		color, three = Red, "WARN"
-	case "error":
-		color, three = Red, "ERROR"
-	case "fatal":
-		color, three = Red, "FATAL"
-	case "panic":
-		color, three = Red, "PANIC"
+	var color, name string
+	switch {
+	case r.Level < slog.LevelDebug:
+		color, name = colorMagenta, "TRACE"
+	case r.Level < slog.LevelInfo:
+		color, name = colorYellow, "DEBUG"
+	case r.Level < slog.LevelWarn:
+		color, name = colorGreen, "INFO"
+	case r.Level < slog.LevelError:
+		color, name = colorRed, "WARN"
 	default:
-		color, three = Gray, "???"
+		color, name = colorRed, "ERROR"
 	}
 
 	b := &strings.Builder{}
-	// pretty console writer
+
 	// header
-	fmt.Fprintf(b, "%s%s%s %s%s%s ", Gray, args.Time, Reset, color, three, Reset)
-	if args.Caller != "" {
-		fmt.Fprintf(b, "%s %s %s>%s", args.Goid, args.Caller, Cyan, Reset)
-	} else {
-		fmt.Fprintf(b, "%s>%s", Cyan, Reset)
+	timestamp := r.Time
+	if timestamp.IsZero() {
+		timestamp = time.Now()
 	}
+	fmt.Fprintf(b, "%s%s%s %s%s%s %s>%s", colorGray, timestamp.Format("2006-01-02T15:04:05.000Z07:00"), colorReset,
+		color, name, colorReset, colorCyan, colorReset)
 
 	// message
-	fmt.Fprintf(b, " %s", args.Message)
+	fmt.Fprintf(b, " %s", r.Message)
 
 	// key and values
-	for _, kv := range args.KeyValues {
-		if kv.ValueType == 's' {
-			kv.Value = strconv.Quote(kv.Value)
+	prefix := ""
+	if len(h.groups) > 0 {
+		prefix = strings.Join(h.groups, ".") + "."
+	}
+	for _, attr := range h.attrs {
+		writeAttr(b, "", attr)
+	}
+	r.Attrs(func(attr slog.Attr) bool {
+		writeAttr(b, prefix, attr)
+		return true
+	})
+	b.WriteString("\n")
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	_, err := io.WriteString(h.out, b.String())
+	return err
+}
+
+// WithAttrs returns a handler that writes attrs with every record
+func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := ""
+	if len(h.groups) > 0 {
+		prefix = strings.Join(h.groups, ".") + "."
+	}
+
+	handler := *h
+	handler.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	handler.attrs = append(handler.attrs, h.attrs...)
+	for _, attr := range attrs {
+		attr.Key = prefix + attr.Key
+		handler.attrs = append(handler.attrs, attr)
+	}
+	return &handler
+}
+
+// WithGroup returns a handler that qualifies the keys of later attributes with name
+func (h *PrettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := *h
+	handler.groups = append(append([]string{}, h.groups...), name)
+	return &handler
+}
+
+// writeAttr writes a key=value pair, expanding groups into dotted keys
+func writeAttr(b *strings.Builder, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if attr.Key != "" {
+			groupPrefix = prefix + attr.Key + "."
 		}
-		if kv.Key == "error" {
-			fmt.Fprintf(b, " %s%s=%s%s", Red, kv.Key, kv.Value, Reset)
-		} else {
-			fmt.Fprintf(b, " %s%s=%s%s%s", Cyan, kv.Key, Gray, kv.Value, Reset)
+		for _, groupAttr := range attr.Value.Group() {
+			writeAttr(b, groupPrefix, groupAttr)
 		}
+		return
 	}
 
-	// stack
-	if args.Stack != "" {
-		b.WriteString("\n")
-		b.WriteString(args.Stack)
-		if args.Stack[len(args.Stack)-1] != '\n' {
-			b.WriteString("\n")
-		}
+	key := prefix + attr.Key
+	value := attr.Value.String()
+	if attr.Value.Kind() == slog.KindString {
+		value = strconv.Quote(value)
+	}
+
+	if attr.Key == "error" {
+		fmt.Fprintf(b, " %s%s=%s%s", colorRed, key, value, colorReset)
 	} else {
-		b.WriteString("\n")
+		fmt.Fprintf(b, " %s%s=%s%s%s", colorCyan, key, colorGray, value, colorReset)
 	}
-	return out.Write([]byte(b.String()))
 }
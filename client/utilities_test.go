@@ -0,0 +1,25 @@
+package client
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrettyHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(NewPrettyHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+
+	logger.Debug("hidden")
+	logger.With("agent", "TEST").WithGroup("request").Info("Request sent", "endpoint", "/my/agent", "status", 200)
+
+	output := buf.String()
+	assert.NotContains(t, output, "hidden")
+	assert.Contains(t, output, "INFO")
+	assert.Contains(t, output, "Request sent")
+	assert.Contains(t, output, `agent=`+colorGray+`"TEST"`)
+	assert.Contains(t, output, `request.endpoint=`+colorGray+`"/my/agent"`)
+	assert.Contains(t, output, `request.status=`+colorGray+`200`)
+}
@@ -1,6 +1,9 @@
 package entities
 
 import (
+	"context"
+	"iter"
+
 	"github.com/jjkirkpatrick/spacetraders-client/client"
 	"github.com/jjkirkpatrick/spacetraders-client/internal/api"
 	"github.com/jjkirkpatrick/spacetraders-client/models"
@@ -12,9 +15,20 @@ type Agent struct {
 }
 
 func ListPublicAgents(c *client.Client) ([]*Agent, error) {
-	fetchFunc := func(meta models.Meta) ([]*Agent, models.Meta, error) {
+	return client.NewPaginator[*Agent](publicAgentPages(c, c.Get)).WithRetryPolicy(c.RetryPolicy()).FetchAllPages()
+}
+
+// IterPublicAgents streams public agents in page order, fetching the following pages while earlier ones are
+// consumed. Breaking out of the loop or cancelling ctx stops fetching.
+func IterPublicAgents(ctx context.Context, c *client.Client) iter.Seq2[*Agent, error] {
+	return client.NewPaginator[*Agent](publicAgentPages(c, contextGet(ctx, c))).WithRetryPolicy(c.RetryPolicy()).All(ctx)
+}
+
+// publicAgentPages returns a function fetching one page of public agents with get
+func publicAgentPages(c *client.Client, get api.GetFunc) func(models.Meta) ([]*Agent, models.Meta, error) {
+	return func(meta models.Meta) ([]*Agent, models.Meta, error) {
 		metaPtr := &meta
-		agents, metaPtr, err := api.ListAgents(c.Get, metaPtr)
+		agents, metaPtr, err := api.ListAgents(get, metaPtr)
 
 		var convertedAgents []*Agent
 		for _, modelAgent := range agents {
@@ -40,7 +54,6 @@ func ListPublicAgents(c *client.Client) ([]*Agent, error) {
 			return convertedAgents, defaultMeta, nil
 		}
 	}
-	return client.NewPaginator[*Agent](fetchFunc).FetchAllPages()
 }
 
 func GetAgent(c *client.Client) (*Agent, error) {
@@ -70,3 +83,10 @@ func GetPublicAgent(c *client.Client, symbol string) (*Agent, error) {
 
 	return agentEntity, nil
 }
+
+// contextGet returns a GetFunc sending requests with ctx
+func contextGet(ctx context.Context, c *client.Client) api.GetFunc {
+	return func(endpoint string, queryParams map[string]string, result interface{}) *models.APIError {
+		return c.GetWithContext(ctx, endpoint, queryParams, result)
+	}
+}
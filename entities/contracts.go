@@ -2,6 +2,7 @@ package entities
 
 import (
 	"context"
+	"iter"
 
 	"github.com/jjkirkpatrick/spacetraders-client/client"
 	"github.com/jjkirkpatrick/spacetraders-client/internal/api"
@@ -37,9 +38,20 @@ func (c *Contract) postFunc() api.PostFunc {
 }
 
 func ListContracts(c *client.Client) ([]*Contract, error) {
-	fetchFunc := func(meta models.Meta) ([]*Contract, models.Meta, error) {
+	return client.NewPaginator[*Contract](contractPages(c, c.Get)).WithRetryPolicy(c.RetryPolicy()).FetchAllPages()
+}
+
+// IterContracts streams contracts in page order, fetching the following pages while earlier ones are
+// consumed. Breaking out of the loop or cancelling ctx stops fetching.
+func IterContracts(ctx context.Context, c *client.Client) iter.Seq2[*Contract, error] {
+	return client.NewPaginator[*Contract](contractPages(c, contextGet(ctx, c))).WithRetryPolicy(c.RetryPolicy()).All(ctx)
+}
+
+// contractPages returns a function fetching one page of contracts with get
+func contractPages(c *client.Client, get api.GetFunc) func(models.Meta) ([]*Contract, models.Meta, error) {
+	return func(meta models.Meta) ([]*Contract, models.Meta, error) {
 		metaPtr := &meta
-		contracts, metaPtr, err := api.ListContracts(c.Get, metaPtr)
+		contracts, metaPtr, err := api.ListContracts(get, metaPtr)
 
 		var convertedContracts []*Contract
 		for _, modelContract := range contracts {
@@ -65,7 +77,6 @@ func ListContracts(c *client.Client) ([]*Contract, error) {
 			return convertedContracts, defaultMeta, nil
 		}
 	}
-	return client.NewPaginator[*Contract](fetchFunc).FetchAllPages()
 }
 
 func GetContract(c *client.Client, symbol string) (*Contract, error) {
@@ -0,0 +1,77 @@
+package entities
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// dryRunServer answers GET requests for a ship in X1-TEST and two systems, counting any
+// other request
+type dryRunServer struct {
+	mutations atomic.Int32
+}
+
+func (d *dryRunServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodGet {
+		d.mutations.Add(1)
+		fmt.Fprint(w, `{"data":{}}`)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/my/ships/TEST-1":
+		fmt.Fprint(w, `{"data":{"symbol":"TEST-1","nav":{"systemSymbol":"X1-TEST","waypointSymbol":"X1-TEST-A1","status":"IN_ORBIT","flightMode":"CRUISE"},"fuel":{"current":50,"capacity":100}}}`)
+	case "/systems":
+		fmt.Fprint(w, `{"data":[{"symbol":"X1-TEST","x":0,"y":0},{"symbol":"X1-WARP","x":30,"y":40}],"meta":{"total":2,"page":1,"limit":20}}`)
+	default:
+		fmt.Fprint(w, `{"data":{}}`)
+	}
+}
+
+func newDryRunClient(t *testing.T, handler http.Handler) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	store := client.NewMemoryTokenStore()
+	require.NoError(t, store.Set("TEST", client.TokenEntry{Token: "token"}))
+
+	options := client.DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.DryRun = true
+
+	c, err := client.NewClient(options)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close(t.Context()) })
+	return c
+}
+
+func TestDryRun_ShipWarp(t *testing.T) {
+	server := &dryRunServer{}
+	c := newDryRunClient(t, server)
+	require.Nil(t, c.Get("/my/ships/TEST-1", nil, nil))
+	require.Nil(t, c.Get("/systems", nil, nil))
+
+	ship := newPreflightShip()
+	ship.Client = c
+	ship.Nav.Status = models.NavStatusInOrbit
+
+	fuel, nav, err := ship.Warp("X1-WARP-A1")
+	require.NoError(t, err)
+	assert.Equal(t, models.NavStatusInTransit, nav.Status)
+	assert.Equal(t, "X1-WARP", nav.SystemSymbol)
+	assert.Equal(t, "X1-WARP-A1", nav.Route.Destination.Symbol)
+	assert.Equal(t, 0, fuel.Current)
+	assert.Equal(t, int32(0), server.mutations.Load(), "simulated warps are not sent to the API")
+}
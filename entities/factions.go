@@ -1,6 +1,9 @@
 package entities
 
 import (
+	"context"
+	"iter"
+
 	"github.com/jjkirkpatrick/spacetraders-client/client"
 	"github.com/jjkirkpatrick/spacetraders-client/internal/api"
 	"github.com/jjkirkpatrick/spacetraders-client/models"
@@ -12,9 +15,20 @@ type Faction struct {
 }
 
 func ListFactions(c *client.Client) ([]*Faction, error) {
-	fetchFunc := func(meta models.Meta) ([]*Faction, models.Meta, error) {
+	return client.NewPaginator[*Faction](factionPages(c, c.Get)).WithRetryPolicy(c.RetryPolicy()).FetchAllPages()
+}
+
+// IterFactions streams factions in page order, fetching the following pages while earlier ones are
+// consumed. Breaking out of the loop or cancelling ctx stops fetching.
+func IterFactions(ctx context.Context, c *client.Client) iter.Seq2[*Faction, error] {
+	return client.NewPaginator[*Faction](factionPages(c, contextGet(ctx, c))).WithRetryPolicy(c.RetryPolicy()).All(ctx)
+}
+
+// factionPages returns a function fetching one page of factions with get
+func factionPages(c *client.Client, get api.GetFunc) func(models.Meta) ([]*Faction, models.Meta, error) {
+	return func(meta models.Meta) ([]*Faction, models.Meta, error) {
 		metaPtr := &meta
-		factions, metaPtr, err := api.ListFactions(c.Get, metaPtr)
+		factions, metaPtr, err := api.ListFactions(get, metaPtr)
 
 		var convertedFactions []*Faction
 		for _, modelFaction := range factions {
@@ -40,7 +54,6 @@ func ListFactions(c *client.Client) ([]*Faction, error) {
 			return convertedFactions, defaultMeta, nil
 		}
 	}
-	return client.NewPaginator[*Faction](fetchFunc).FetchAllPages()
 }
 
 func GetFaction(c *client.Client, symbol string) (*Faction, error) {
@@ -0,0 +1,317 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// GameStateCollectorOptions configures a GameStateCollector
+type GameStateCollectorOptions struct {
+	// Interval between samples (default: 1 minute)
+	Interval time.Duration
+	// MaxRequestsPerSample caps the API requests a single sample may spend.
+	// The fleet and contracts are skipped for a sample, keeping their previous values,
+	// when their pages would exceed the remaining budget. 0 means no limit.
+	MaxRequestsPerSample int
+}
+
+// DefaultGameStateCollectorOptions returns the default configuration for a GameStateCollector
+func DefaultGameStateCollectorOptions() GameStateCollectorOptions {
+	return GameStateCollectorOptions{
+		Interval:             time.Minute,
+		MaxRequestsPerSample: 10,
+	}
+}
+
+// shipSample holds the state of a single ship at the last sample
+type shipSample struct {
+	symbol     string
+	role       string
+	navStatus  string
+	cargoUnits int
+	cargoCap   int
+	fuel       int
+	fuelCap    int
+	onCooldown bool
+}
+
+// GameStateCollector periodically samples the agent, fleet and contracts and exports
+// them as observable gauges through the client's meter
+type GameStateCollector struct {
+	client  *client.Client
+	options GameStateCollectorOptions
+
+	mu              sync.RWMutex
+	credits         int64
+	haveAgent       bool
+	ships           []shipSample
+	activeContracts int64
+	contractValue   int64
+	haveContracts   bool
+	contractPages   int
+	lastSample      time.Time
+
+	registration metric.Registration
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+// NewGameStateCollector creates a collector and registers its gauges on the client's meter.
+// The client must have telemetry metrics enabled.
+func NewGameStateCollector(c *client.Client, options GameStateCollectorOptions) (*GameStateCollector, error) {
+	meter := c.Meter()
+	if meter == nil {
+		return nil, fmt.Errorf("game state metrics require telemetry metrics to be enabled")
+	}
+
+	if options.Interval <= 0 {
+		options.Interval = DefaultGameStateCollectorOptions().Interval
+	}
+
+	collector := &GameStateCollector{
+		client:        c,
+		options:       options,
+		contractPages: 1,
+	}
+
+	credits, err := meter.Int64ObservableGauge("game_credits",
+		metric.WithDescription("Agent credits"),
+		metric.WithUnit("{credit}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credits gauge: %w", err)
+	}
+
+	shipCount, err := meter.Int64ObservableGauge("game_ships",
+		metric.WithDescription("Number of ships by role and nav status"),
+		metric.WithUnit("{ship}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ship count gauge: %w", err)
+	}
+
+	cargoUtilisation, err := meter.Float64ObservableGauge("game_ship_cargo_utilisation",
+		metric.WithDescription("Fraction of each ship's cargo capacity in use"),
+		metric.WithUnit("1"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cargo utilisation gauge: %w", err)
+	}
+
+	fuel, err := meter.Int64ObservableGauge("game_ship_fuel",
+		metric.WithDescription("Fuel held by each ship"),
+		metric.WithUnit("{fuel}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fuel gauge: %w", err)
+	}
+
+	fuelCapacity, err := meter.Int64ObservableGauge("game_ship_fuel_capacity",
+		metric.WithDescription("Fuel capacity of each ship"),
+		metric.WithUnit("{fuel}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create fuel capacity gauge: %w", err)
+	}
+
+	activeContracts, err := meter.Int64ObservableGauge("game_contracts_active",
+		metric.WithDescription("Number of accepted contracts not yet fulfilled"),
+		metric.WithUnit("{contract}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create active contracts gauge: %w", err)
+	}
+
+	contractValue, err := meter.Int64ObservableGauge("game_contracts_value",
+		metric.WithDescription("Total payment of active contracts"),
+		metric.WithUnit("{credit}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contract value gauge: %w", err)
+	}
+
+	shipsOnCooldown, err := meter.Int64ObservableGauge("game_ships_on_cooldown",
+		metric.WithDescription("Number of ships with an active cooldown"),
+		metric.WithUnit("{ship}"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cooldown gauge: %w", err)
+	}
+
+	collector.registration, err = meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		collector.mu.RLock()
+		defer collector.mu.RUnlock()
+
+		if collector.lastSample.IsZero() {
+			return nil
+		}
+
+		agentAttr := attribute.String("agent", c.AgentSymbol)
+
+		if collector.haveAgent {
+			o.ObserveInt64(credits, collector.credits, metric.WithAttributes(agentAttr))
+		}
+
+		type roleStatus struct{ role, status string }
+		counts := make(map[roleStatus]int64)
+		var cooling int64
+
+		for _, ship := range collector.ships {
+			counts[roleStatus{ship.role, ship.navStatus}]++
+			if ship.onCooldown {
+				cooling++
+			}
+
+			shipAttrs := metric.WithAttributes(agentAttr,
+				attribute.String("ship", ship.symbol),
+				attribute.String("role", ship.role))
+
+			if ship.cargoCap > 0 {
+				o.ObserveFloat64(cargoUtilisation, float64(ship.cargoUnits)/float64(ship.cargoCap), shipAttrs)
+			}
+			if ship.fuelCap > 0 {
+				o.ObserveInt64(fuel, int64(ship.fuel), shipAttrs)
+				o.ObserveInt64(fuelCapacity, int64(ship.fuelCap), shipAttrs)
+			}
+		}
+
+		for key, count := range counts {
+			o.ObserveInt64(shipCount, count, metric.WithAttributes(agentAttr,
+				attribute.String("role", key.role),
+				attribute.String("nav_status", key.status)))
+		}
+
+		o.ObserveInt64(shipsOnCooldown, cooling, metric.WithAttributes(agentAttr))
+		if collector.haveContracts {
+			o.ObserveInt64(activeContracts, collector.activeContracts, metric.WithAttributes(agentAttr))
+			o.ObserveInt64(contractValue, collector.contractValue, metric.WithAttributes(agentAttr))
+		}
+
+		return nil
+	}, credits, shipCount, cargoUtilisation, fuel, fuelCapacity, activeContracts, contractValue, shipsOnCooldown)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register game state callback: %w", err)
+	}
+
+	return collector, nil
+}
+
+// Start samples immediately and then at every interval until Stop is called or ctx is cancelled
+func (g *GameStateCollector) Start(ctx context.Context) {
+	ctx, g.cancel = context.WithCancel(ctx)
+	g.done = make(chan struct{})
+
+	go func() {
+		defer close(g.done)
+
+		ticker := time.NewTicker(g.options.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := g.Sample(); err != nil {
+				g.client.Logger.Warn("Game state sample failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops periodic sampling and unregisters the gauges
+func (g *GameStateCollector) Stop() error {
+	if g.cancel != nil {
+		g.cancel()
+		<-g.done
+	}
+	return g.registration.Unregister()
+}
+
+// Sample fetches the agent, fleet and contracts once, within the configured request budget
+func (g *GameStateCollector) Sample() error {
+	budget := g.options.MaxRequestsPerSample
+	if budget <= 0 {
+		budget = math.MaxInt
+	}
+
+	agent, err := GetAgent(g.client)
+	if err != nil {
+		return fmt.Errorf("failed to sample agent: %w", err)
+	}
+	budget--
+
+	g.mu.Lock()
+	g.credits = agent.Credits
+	g.haveAgent = true
+	g.lastSample = time.Now()
+	g.mu.Unlock()
+
+	shipPages := int(math.Max(1, math.Ceil(float64(agent.ShipCount)/20)))
+	if shipPages <= budget {
+		ships, err := ListShips(g.client)
+		if err != nil {
+			return fmt.Errorf("failed to sample fleet: %w", err)
+		}
+		budget -= shipPages
+
+		now := time.Now()
+		samples := make([]shipSample, 0, len(ships))
+		for _, ship := range ships {
+			onCooldown := false
+			if expiration, err := time.Parse(time.RFC3339, ship.Cooldown.Expiration); err == nil {
+				onCooldown = ship.Cooldown.RemainingSeconds > 0 && expiration.After(now)
+			}
+
+			samples = append(samples, shipSample{
+				symbol:     ship.Symbol,
+				role:       string(ship.Registration.Role),
+				navStatus:  string(ship.Nav.Status),
+				cargoUnits: ship.Cargo.Units,
+				cargoCap:   ship.Cargo.Capacity,
+				fuel:       ship.Fuel.Current,
+				fuelCap:    ship.Fuel.Capacity,
+				onCooldown: onCooldown,
+			})
+		}
+
+		g.mu.Lock()
+		g.ships = samples
+		g.mu.Unlock()
+	} else {
+		g.client.Logger.Debug("Skipping fleet sample, request budget exceeded",
+			"pages", shipPages, "budget", budget)
+	}
+
+	g.mu.RLock()
+	contractPages := g.contractPages
+	g.mu.RUnlock()
+
+	if contractPages <= budget {
+		contracts, err := ListContracts(g.client)
+		if err != nil {
+			return fmt.Errorf("failed to sample contracts: %w", err)
+		}
+
+		var active, value int64
+		for _, contract := range contracts {
+			if contract.Accepted && !contract.Fulfilled {
+				active++
+				value += int64(contract.Terms.Payment.OnAccepted + contract.Terms.Payment.OnFulfilled)
+			}
+		}
+
+		g.mu.Lock()
+		g.activeContracts = active
+		g.contractValue = value
+		g.haveContracts = true
+		g.contractPages = int(math.Max(1, math.Ceil(float64(len(contracts))/20)))
+		g.mu.Unlock()
+	} else {
+		g.client.Logger.Debug("Skipping contract sample, request budget exceeded",
+			"pages", contractPages, "budget", budget)
+	}
+
+	return nil
+}
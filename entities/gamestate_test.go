@@ -0,0 +1,191 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+	"github.com/jjkirkpatrick/spacetraders-client/telemetry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// gameStateServer serves an agent, its fleet and contracts, counting the requests for each
+type gameStateServer struct {
+	mu        sync.Mutex
+	credits   int
+	shipCount int
+	ships     []string
+	requests  map[string]int
+}
+
+func (g *gameStateServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if strings.HasPrefix(r.URL.Path, "/my/") {
+		g.requests[r.URL.Path]++
+	}
+
+	switch r.URL.Path {
+	case "/my/agent":
+		fmt.Fprintf(w, `{"data":{"symbol":"TEST","credits":%d,"shipCount":%d}}`, g.credits, g.shipCount)
+	case "/my/ships":
+		fmt.Fprintf(w, `{"data":[%s],"meta":{"total":%d,"page":1,"limit":20}}`, strings.Join(g.ships, ","), len(g.ships))
+	case "/my/contracts":
+		fmt.Fprint(w, `{"data":[
+			{"id":"c1","accepted":true,"fulfilled":false,"terms":{"payment":{"onAccepted":1000,"onFulfilled":5000}}},
+			{"id":"c2","accepted":true,"fulfilled":true,"terms":{"payment":{"onAccepted":2000,"onFulfilled":3000}}},
+			{"id":"c3","accepted":false,"fulfilled":false,"terms":{"payment":{"onAccepted":4000,"onFulfilled":4000}}}
+		],"meta":{"total":3,"page":1,"limit":20}}`)
+	case "/systems/X1-TEST":
+		fmt.Fprint(w, `{"data":{"symbol":"X1-TEST"}}`)
+	case "/systems/X1-TEST/waypoints":
+		fmt.Fprint(w, `{"data":[],"meta":{"total":0,"page":1,"limit":20}}`)
+	default:
+		fmt.Fprint(w, `{"data":{}}`)
+	}
+}
+
+// set replaces the agent's credits and fleet
+func (g *gameStateServer) set(credits, shipCount int, ships ...string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.credits = credits
+	g.shipCount = shipCount
+	g.ships = ships
+}
+
+// count returns and resets the number of requests made for path
+func (g *gameStateServer) count(path string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	count := g.requests[path]
+	g.requests[path] = 0
+	return count
+}
+
+func gameStateShip(symbol, role, status string, cargo, fuel int) string {
+	ship, _ := json.Marshal(map[string]interface{}{
+		"symbol":       symbol,
+		"registration": map[string]string{"role": role},
+		"nav":          map[string]string{"systemSymbol": "X1-TEST", "waypointSymbol": "X1-TEST-A1", "status": status},
+		"cargo":        map[string]int{"capacity": 40, "units": cargo},
+		"fuel":         map[string]int{"capacity": 400, "current": fuel},
+	})
+	return string(ship)
+}
+
+func newGameStateClient(t *testing.T, server *gameStateServer) *client.Client {
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	store := client.NewMemoryTokenStore()
+	require.NoError(t, store.Set("TEST", client.TokenEntry{Token: "token"}))
+
+	options := client.DefaultClientOptions()
+	options.BaseURL = httpServer.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+	options.TelemetryOptions = &client.TelemetryOptions{
+		ServiceName:    "test",
+		MetricExporter: telemetry.ExporterMemory,
+		EnableMetrics:  true,
+	}
+
+	c, err := client.NewClient(options)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close(context.Background()) })
+	return c
+}
+
+// gauge returns the values of an int64 gauge by the value of attribute key
+func gauge(t *testing.T, c *client.Client, name, key string) map[string]int64 {
+	m, ok, err := c.InMemoryTelemetry().Metric(context.Background(), name)
+	require.NoError(t, err)
+	require.True(t, ok, "%s was not observed", name)
+
+	values := make(map[string]int64)
+	for _, point := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+		value, _ := point.Attributes.Value(attribute.Key(key))
+		values[value.AsString()] = point.Value
+	}
+	return values
+}
+
+func TestGameStateCollector(t *testing.T) {
+	server := &gameStateServer{requests: make(map[string]int)}
+	server.set(175000, 2,
+		gameStateShip("TEST-1", "COMMAND", "DOCKED", 10, 300),
+		gameStateShip("TEST-2", "EXCAVATOR", "IN_ORBIT", 40, 100))
+	c := newGameStateClient(t, server)
+
+	collector, err := NewGameStateCollector(c, DefaultGameStateCollectorOptions())
+	require.NoError(t, err)
+	defer collector.Stop()
+
+	require.NoError(t, collector.Sample())
+	assert.Equal(t, 1, server.count("/my/agent"))
+	assert.Equal(t, 1, server.count("/my/ships"))
+	assert.Equal(t, 1, server.count("/my/contracts"))
+
+	assert.Equal(t, map[string]int64{"TEST": 175000}, gauge(t, c, "game_credits", "agent"))
+	assert.Equal(t, map[string]int64{"COMMAND": 1, "EXCAVATOR": 1}, gauge(t, c, "game_ships", "role"))
+	assert.Equal(t, map[string]int64{"TEST-1": 300, "TEST-2": 100}, gauge(t, c, "game_ship_fuel", "ship"))
+	assert.Equal(t, map[string]int64{"TEST": 1}, gauge(t, c, "game_contracts_active", "agent"))
+	assert.Equal(t, map[string]int64{"TEST": 6000}, gauge(t, c, "game_contracts_value", "agent"))
+
+	utilisation, ok, err := c.InMemoryTelemetry().Metric(context.Background(), "game_ship_cargo_utilisation")
+	require.NoError(t, err)
+	require.True(t, ok)
+	cargo := make(map[string]float64)
+	for _, point := range utilisation.Data.(metricdata.Gauge[float64]).DataPoints {
+		ship, _ := point.Attributes.Value("ship")
+		cargo[ship.AsString()] = point.Value
+	}
+	assert.Equal(t, map[string]float64{"TEST-1": 0.25, "TEST-2": 1}, cargo)
+}
+
+func TestGameStateCollector_RequestBudget(t *testing.T) {
+	server := &gameStateServer{requests: make(map[string]int)}
+	server.set(1000, 1, gameStateShip("TEST-1", "COMMAND", "DOCKED", 0, 300))
+	c := newGameStateClient(t, server)
+
+	options := DefaultGameStateCollectorOptions()
+	options.MaxRequestsPerSample = 2
+	collector, err := NewGameStateCollector(c, options)
+	require.NoError(t, err)
+	defer collector.Stop()
+
+	// The agent and the single page of ships use the whole budget, so contracts are skipped
+	require.NoError(t, collector.Sample())
+	assert.Equal(t, 1, server.count("/my/agent"))
+	assert.Equal(t, 1, server.count("/my/ships"))
+	assert.Equal(t, 0, server.count("/my/contracts"))
+
+	// Contracts that were never sampled are not reported as zero
+	_, observed, err := c.InMemoryTelemetry().Metric(context.Background(), "game_contracts_active")
+	require.NoError(t, err)
+	assert.False(t, observed, "contracts are not observed until they are sampled")
+
+	// A fleet needing three pages no longer fits, so it keeps its previous values
+	server.set(2000, 60, gameStateShip("TEST-1", "COMMAND", "IN_ORBIT", 0, 100))
+	require.NoError(t, collector.Sample())
+	assert.Equal(t, 1, server.count("/my/agent"))
+	assert.Equal(t, 0, server.count("/my/ships"))
+	assert.Equal(t, 1, server.count("/my/contracts"))
+
+	assert.Equal(t, map[string]int64{"TEST": 2000}, gauge(t, c, "game_credits", "agent"))
+	assert.Equal(t, map[string]int64{"TEST-1": 300}, gauge(t, c, "game_ship_fuel", "ship"))
+	assert.Equal(t, map[string]int64{"TEST": 1}, gauge(t, c, "game_contracts_active", "agent"))
+}
@@ -0,0 +1,66 @@
+package entities
+
+import (
+	"context"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+)
+
+// SetAutoPosition enables or disables automatic positioning before ship actions.
+// When enabled, actions dock or orbit the ship as required and wait for an
+// in-transit ship to arrive before running.
+func (s *Ship) SetAutoPosition(enabled bool) {
+	s.autoPosition = enabled
+}
+
+// position waits out any transit and moves the ship into the nav status the action requires
+func (s *Ship) position(action ShipAction) error {
+	if s.Nav.Status == models.NavStatusInTransit {
+		if err := s.WaitForArrival(); err != nil {
+			return err
+		}
+	}
+
+	switch actionNavStatus[action] {
+	case models.NavStatusDocked:
+		_, err := s.Dock()
+		return err
+	case models.NavStatusInOrbit:
+		_, err := s.Orbit()
+		return err
+	}
+
+	return nil
+}
+
+// WaitForArrival blocks until an in-transit ship reaches its destination or the
+// ship's context is cancelled. The ship is then in orbit at its destination.
+func (s *Ship) WaitForArrival() error {
+	if s.Nav.Status != models.NavStatusInTransit {
+		return nil
+	}
+
+	ctx := s.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if remaining := s.arrivalRemaining(); remaining > 0 {
+		timer := time.NewTimer(remaining)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	// The API reports arrived ships as in orbit at the destination, so update
+	// locally rather than spending a request to find that out
+	s.Nav.Status = models.NavStatusInOrbit
+	s.Nav.WaypointSymbol = s.Nav.Route.Destination.Symbol
+	s.Nav.SystemSymbol = s.Nav.Route.Destination.SystemSymbol
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package entities
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// navServer answers dock and orbit requests, recording each one
+type navServer struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (n *navServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	status := models.NavStatusInOrbit
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/dock"):
+		status = models.NavStatusDocked
+	case !strings.HasSuffix(r.URL.Path, "/orbit"):
+		fmt.Fprint(w, `{"data":{}}`)
+		return
+	}
+
+	n.mu.Lock()
+	n.requests = append(n.requests, r.URL.Path)
+	n.mu.Unlock()
+	fmt.Fprintf(w, `{"data":{"nav":{"systemSymbol":"X1-TEST","waypointSymbol":"X1-TEST-B2","status":"%s"}}}`, status)
+}
+
+func (n *navServer) sent() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]string{}, n.requests...)
+}
+
+// newPositioningShip returns an auto-positioning ship in transit to X1-TEST-B2, arriving after arrival
+func newPositioningShip(t *testing.T, nav *navServer, arrival time.Duration) *Ship {
+	ship := newPreflightShip()
+	ship.Client = newStatusClient(t, nav)
+	ship.SetAutoPosition(true)
+	ship.Nav.Status = models.NavStatusInTransit
+	ship.Nav.Route.Destination = models.RouteWaypoint{Symbol: "X1-TEST-B2", SystemSymbol: "X1-TEST"}
+	ship.Nav.Route.Arrival = time.Now().Add(arrival).Format(time.RFC3339Nano)
+	return ship
+}
+
+func TestPosition_DockOrOrbit(t *testing.T) {
+	nav := &navServer{}
+	ship := newPositioningShip(t, nav, -time.Minute)
+	ship.Nav.Status = models.NavStatusInOrbit
+
+	// Actions needing the ship docked dock it once
+	require.NoError(t, ship.prepare(ActionSellCargo))
+	require.NoError(t, ship.prepare(ActionRefuel))
+	assert.Equal(t, models.NavStatusDocked, ship.Nav.Status)
+	assert.Equal(t, []string{"/my/ships/TEST-1/dock"}, nav.sent())
+
+	// Actions needing orbit undock it, and actions without a requirement leave it be
+	require.NoError(t, ship.prepare(ActionExtract))
+	require.NoError(t, ship.prepare(ActionJettison))
+	assert.Equal(t, models.NavStatusInOrbit, ship.Nav.Status)
+	assert.Equal(t, []string{"/my/ships/TEST-1/dock", "/my/ships/TEST-1/orbit"}, nav.sent())
+}
+
+func TestPosition_WaitsForArrival(t *testing.T) {
+	nav := &navServer{}
+	ship := newPositioningShip(t, nav, 50*time.Millisecond)
+
+	start := time.Now()
+	require.NoError(t, ship.prepare(ActionSellCargo))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	assert.Equal(t, models.NavStatusDocked, ship.Nav.Status)
+	assert.Equal(t, []string{"/my/ships/TEST-1/dock"}, nav.sent())
+}
+
+func TestOrbit_AfterArrival(t *testing.T) {
+	nav := &navServer{}
+	ship := newPositioningShip(t, nav, -time.Minute)
+
+	// The arrived ship is already in orbit, so no request is needed
+	_, err := ship.Orbit()
+	require.NoError(t, err)
+	assert.Equal(t, models.NavStatusInOrbit, ship.Nav.Status)
+	assert.Equal(t, "X1-TEST-B2", ship.Nav.WaypointSymbol)
+	assert.Empty(t, nav.sent())
+}
+
+func TestWaitForArrival_Cancelled(t *testing.T) {
+	nav := &navServer{}
+	ship := newPositioningShip(t, nav, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ship.SetContext(ctx)
+
+	err := ship.WaitForArrival()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, models.NavStatusInTransit, ship.Nav.Status)
+
+	_, err = ship.Dock()
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, nav.sent())
+}
@@ -0,0 +1,360 @@
+package entities
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+)
+
+// ShipAction identifies a ship operation for preflight validation and auto-positioning
+type ShipAction string
+
+const (
+	ActionOrbit         ShipAction = "orbit"
+	ActionDock          ShipAction = "dock"
+	ActionExtract       ShipAction = "extract"
+	ActionSiphon        ShipAction = "siphon"
+	ActionSurvey        ShipAction = "survey"
+	ActionRefine        ShipAction = "refine"
+	ActionJettison      ShipAction = "jettison"
+	ActionJump          ShipAction = "jump"
+	ActionNavigate      ShipAction = "navigate"
+	ActionWarp          ShipAction = "warp"
+	ActionSellCargo     ShipAction = "sell_cargo"
+	ActionPurchaseCargo ShipAction = "purchase_cargo"
+	ActionTransferCargo ShipAction = "transfer_cargo"
+	ActionRefuel        ShipAction = "refuel"
+	ActionRepair        ShipAction = "repair"
+	ActionScrap         ShipAction = "scrap"
+	ActionInstallMount  ShipAction = "install_mount"
+	ActionRemoveMount   ShipAction = "remove_mount"
+	ActionNegotiate     ShipAction = "negotiate_contract"
+)
+
+// Sentinel errors returned (wrapped in a PreflightError) when a ship action
+// is known to fail from the ship's current local state
+var (
+	ErrShipInTransit         = errors.New("ship is in transit")
+	ErrShipNotDocked         = errors.New("ship is not docked")
+	ErrShipNotInOrbit        = errors.New("ship is not in orbit")
+	ErrCooldownActive        = errors.New("ship is on cooldown")
+	ErrMissingMount          = errors.New("ship has no suitable mount")
+	ErrInsufficientFuel      = errors.New("insufficient fuel")
+	ErrInsufficientCargo     = errors.New("insufficient cargo")
+	ErrInsufficientCargoRoom = errors.New("insufficient cargo space")
+	ErrInvalidUnits          = errors.New("units must be greater than zero")
+	ErrNoFreeMountingPoint   = errors.New("no free mounting point")
+	ErrInsufficientPower     = errors.New("insufficient reactor power")
+)
+
+// PreflightError describes an action rejected locally before any request was sent
+type PreflightError struct {
+	Ship   string
+	Action ShipAction
+	Err    error
+	Detail string
+}
+
+func (e *PreflightError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("preflight %s for ship %s: %v", e.Action, e.Ship, e.Err)
+	}
+	return fmt.Sprintf("preflight %s for ship %s: %v: %s", e.Action, e.Ship, e.Err, e.Detail)
+}
+
+func (e *PreflightError) Unwrap() error {
+	return e.Err
+}
+
+// IsPreflightError checks if an error was produced by preflight validation
+func IsPreflightError(err error) bool {
+	var preflightErr *PreflightError
+	return errors.As(err, &preflightErr)
+}
+
+// actionNavStatus lists the nav status each action requires
+var actionNavStatus = map[ShipAction]models.NavStatus{
+	ActionExtract:       models.NavStatusInOrbit,
+	ActionSiphon:        models.NavStatusInOrbit,
+	ActionSurvey:        models.NavStatusInOrbit,
+	ActionJump:          models.NavStatusInOrbit,
+	ActionNavigate:      models.NavStatusInOrbit,
+	ActionWarp:          models.NavStatusInOrbit,
+	ActionSellCargo:     models.NavStatusDocked,
+	ActionPurchaseCargo: models.NavStatusDocked,
+	ActionRefuel:        models.NavStatusDocked,
+	ActionRepair:        models.NavStatusDocked,
+	ActionScrap:         models.NavStatusDocked,
+	ActionInstallMount:  models.NavStatusDocked,
+	ActionRemoveMount:   models.NavStatusDocked,
+	ActionNegotiate:     models.NavStatusDocked,
+}
+
+// cooldownActions lists the actions that cannot run while the ship's reactor is cooling down
+var cooldownActions = map[ShipAction]bool{
+	ActionExtract: true,
+	ActionSiphon:  true,
+	ActionSurvey:  true,
+	ActionRefine:  true,
+	ActionJump:    true,
+}
+
+// MountPowerRequirements holds the reactor power used by mounts that are not yet installed.
+// Preflight prefers the requirements of an identical installed mount when one exists.
+var MountPowerRequirements = map[models.MountSymbol]int{
+	models.MountGasSiphonI:       1,
+	models.MountGasSiphonII:      2,
+	models.MountGasSiphonIII:     3,
+	models.MountSurveyorI:        1,
+	models.MountSurveyorII:       2,
+	models.MountSurveyorIII:      3,
+	models.MountSensorArrayI:     1,
+	models.MountSensorArrayII:    2,
+	models.MountSensorArrayIII:   3,
+	models.MountMiningLaserI:     1,
+	models.MountMiningLaserII:    2,
+	models.MountMiningLaserIII:   3,
+	models.MountLaserCannonI:     2,
+	models.MountMissileLauncherI: 1,
+	models.MountTurretI:          1,
+}
+
+// SetPreflight enables or disables local validation of ship actions.
+// When enabled, actions that are certain to fail return a *PreflightError
+// without spending a rate-limited request.
+func (s *Ship) SetPreflight(enabled bool) {
+	s.preflight = enabled
+}
+
+// prepare positions the ship for an action and runs its preflight checks,
+// according to which of the two modes are enabled
+func (s *Ship) prepare(action ShipAction, checks ...func() error) error {
+	if s.autoPosition {
+		if err := s.position(action); err != nil {
+			return err
+		}
+	}
+
+	if !s.preflight {
+		return nil
+	}
+
+	if err := s.checkNavStatus(action); err != nil {
+		return err
+	}
+
+	if cooldownActions[action] {
+		if remaining := s.cooldownRemaining(); remaining > 0 {
+			return s.preflightError(action, ErrCooldownActive, "%s remaining", remaining.Round(time.Second))
+		}
+	}
+
+	for _, check := range checks {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Ship) preflightError(action ShipAction, err error, format string, args ...interface{}) *PreflightError {
+	return &PreflightError{
+		Ship:   s.Symbol,
+		Action: action,
+		Err:    err,
+		Detail: fmt.Sprintf(format, args...),
+	}
+}
+
+// currentNavStatus returns the ship's nav status, treating a ship whose arrival time
+// has already passed as in orbit at its destination
+func (s *Ship) currentNavStatus() models.NavStatus {
+	if s.Nav.Status == models.NavStatusInTransit && s.arrivalRemaining() <= 0 {
+		return models.NavStatusInOrbit
+	}
+	return s.Nav.Status
+}
+
+// arrivalRemaining returns how long until the ship arrives at its destination
+func (s *Ship) arrivalRemaining() time.Duration {
+	arrival, err := time.Parse(time.RFC3339, s.Nav.Route.Arrival)
+	if err != nil {
+		return 0
+	}
+	return time.Until(arrival)
+}
+
+// cooldownRemaining returns how long until the ship's cooldown expires
+func (s *Ship) cooldownRemaining() time.Duration {
+	if s.Cooldown.RemainingSeconds <= 0 {
+		return 0
+	}
+	expiration, err := time.Parse(time.RFC3339, s.Cooldown.Expiration)
+	if err != nil {
+		return 0
+	}
+	return time.Until(expiration)
+}
+
+func (s *Ship) checkNavStatus(action ShipAction) error {
+	status := s.currentNavStatus()
+	if status == models.NavStatusInTransit {
+		return s.preflightError(action, ErrShipInTransit, "arrives at %s in %s",
+			s.Nav.Route.Destination.Symbol, s.arrivalRemaining().Round(time.Second))
+	}
+
+	required, ok := actionNavStatus[action]
+	if !ok || status == required {
+		return nil
+	}
+
+	if required == models.NavStatusDocked {
+		return s.preflightError(action, ErrShipNotDocked, "status is %s", status)
+	}
+	return s.preflightError(action, ErrShipNotInOrbit, "status is %s", status)
+}
+
+// hasMount checks if the ship has a mount whose symbol starts with prefix
+func (s *Ship) hasMount(prefix string) bool {
+	for _, mount := range s.Mounts {
+		if strings.HasPrefix(mount.Symbol, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cargoUnits returns the number of units of a good held in the ship's cargo
+func (s *Ship) cargoUnits(goodSymbol models.GoodSymbol) int {
+	for _, item := range s.Cargo.Inventory {
+		if item.Symbol == string(goodSymbol) {
+			return item.Units
+		}
+	}
+	return 0
+}
+
+func (s *Ship) checkMount(action ShipAction, prefix string) func() error {
+	return func() error {
+		if !s.hasMount(prefix) {
+			return s.preflightError(action, ErrMissingMount, "requires a %s mount", strings.TrimPrefix(prefix, "MOUNT_"))
+		}
+		return nil
+	}
+}
+
+func (s *Ship) checkCargoSpace(action ShipAction, units int) func() error {
+	return func() error {
+		available := s.Cargo.Capacity - s.Cargo.Units
+		if available < units {
+			return s.preflightError(action, ErrInsufficientCargoRoom, "%d units free, %d required", available, units)
+		}
+		return nil
+	}
+}
+
+func (s *Ship) checkCargoHeld(action ShipAction, goodSymbol models.GoodSymbol, units int) func() error {
+	return func() error {
+		if units <= 0 {
+			return s.preflightError(action, ErrInvalidUnits, "requested %d units of %s", units, goodSymbol)
+		}
+		if held := s.cargoUnits(goodSymbol); held < units {
+			return s.preflightError(action, ErrInsufficientCargo, "holding %d units of %s, %d requested", held, goodSymbol, units)
+		}
+		return nil
+	}
+}
+
+func (s *Ship) checkUnits(action ShipAction, units int) func() error {
+	return func() error {
+		if units <= 0 {
+			return s.preflightError(action, ErrInvalidUnits, "requested %d units", units)
+		}
+		return nil
+	}
+}
+
+// checkNavigateFuel checks the ship holds enough fuel to reach a waypoint in the
+// current system using its current flight mode
+func (s *Ship) checkNavigateFuel(waypointSymbol string) func() error {
+	return func() error {
+		if s.Fuel.Capacity == 0 {
+			return nil
+		}
+
+		edges, ok := s.Graph[s.Nav.WaypointSymbol][waypointSymbol]
+		if !ok {
+			// Destination is outside the known graph, let the API decide
+			return nil
+		}
+
+		var distance float64
+		for _, edge := range edges {
+			if edge != nil {
+				distance = edge.Distance
+				break
+			}
+		}
+
+		flightMode := s.Nav.FlightMode
+		if flightMode == "" {
+			flightMode = models.FlightModeCruise
+		}
+
+		required := s.CalculateFuelRequired(distance, flightMode)
+		if s.Fuel.Current < required {
+			return s.preflightError(ActionNavigate, ErrInsufficientFuel, "%s to %s needs %d fuel, %d available",
+				flightMode, waypointSymbol, required, s.Fuel.Current)
+		}
+		return nil
+	}
+}
+
+// checkInstallMount checks the mount is in cargo and the ship has a free mounting point
+// and enough reactor power to run it
+func (s *Ship) checkInstallMount(mountSymbol models.MountSymbol) func() error {
+	return func() error {
+		if s.cargoUnits(models.GoodSymbol(mountSymbol)) < 1 {
+			return s.preflightError(ActionInstallMount, ErrInsufficientCargo, "%s is not in cargo", mountSymbol)
+		}
+
+		if len(s.Mounts) >= s.Frame.MountingPoints {
+			return s.preflightError(ActionInstallMount, ErrNoFreeMountingPoint, "%d of %d mounting points in use",
+				len(s.Mounts), s.Frame.MountingPoints)
+		}
+
+		required, known := MountPowerRequirements[mountSymbol]
+		for _, mount := range s.Mounts {
+			if mount.Symbol == string(mountSymbol) {
+				required, known = mount.Requirements.Power, true
+				break
+			}
+		}
+		if !known {
+			return nil
+		}
+
+		available := s.Reactor.PowerOutput - s.powerUsed()
+		if available < required {
+			return s.preflightError(ActionInstallMount, ErrInsufficientPower, "%s needs %d power, %d available",
+				mountSymbol, required, available)
+		}
+		return nil
+	}
+}
+
+// powerUsed returns the reactor power consumed by the ship's installed components
+func (s *Ship) powerUsed() int {
+	used := s.Frame.Requirements.Power + s.Engine.Requirements.Power
+	for _, module := range s.Modules {
+		used += module.Requirements.Power
+	}
+	for _, mount := range s.Mounts {
+		used += mount.Requirements.Power
+	}
+	return used
+}
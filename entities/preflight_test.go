@@ -0,0 +1,349 @@
+package entities
+
+import (
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPreflightShip() *Ship {
+	ship := &Ship{preflight: true}
+	ship.Symbol = "TEST-1"
+	ship.Nav = models.ShipNav{
+		SystemSymbol:   "X1-TEST",
+		WaypointSymbol: "X1-TEST-A1",
+		Status:         models.NavStatusDocked,
+		FlightMode:     models.FlightModeCruise,
+	}
+	ship.Cargo = models.Cargo{
+		Capacity: 40,
+		Units:    10,
+		Inventory: []models.Inventory{
+			{Symbol: string(models.IronOre), Units: 10},
+		},
+	}
+	ship.Fuel = models.FuelDetails{Current: 50, Capacity: 100}
+	ship.Frame.MountingPoints = 1
+	ship.Reactor.PowerOutput = 10
+	ship.Graph = models.Graph{
+		"X1-TEST-A1": {
+			"X1-TEST-B2": {
+				models.FlightModeCruise: &models.Edge{Distance: 80},
+			},
+		},
+	}
+	return ship
+}
+
+func TestPreflight_NavStatus(t *testing.T) {
+	ship := newPreflightShip()
+
+	err := ship.prepare(ActionExtract)
+	assert.True(t, errors.Is(err, ErrShipNotInOrbit))
+	assert.True(t, IsPreflightError(err))
+
+	ship.Nav.Status = models.NavStatusInOrbit
+	err = ship.prepare(ActionSellCargo)
+	assert.True(t, errors.Is(err, ErrShipNotDocked))
+}
+
+func TestPreflight_InTransit(t *testing.T) {
+	ship := newPreflightShip()
+	ship.Nav.Status = models.NavStatusInTransit
+	ship.Nav.Route.Arrival = time.Now().Add(time.Minute).Format(time.RFC3339)
+
+	err := ship.prepare(ActionNavigate)
+	assert.True(t, errors.Is(err, ErrShipInTransit))
+
+	// A ship whose arrival time has passed is treated as in orbit
+	ship.Nav.Route.Arrival = time.Now().Add(-time.Minute).Format(time.RFC3339)
+	assert.NoError(t, ship.prepare(ActionNavigate))
+}
+
+func TestPreflight_Extract(t *testing.T) {
+	ship := newPreflightShip()
+	ship.Nav.Status = models.NavStatusInOrbit
+
+	err := ship.prepare(ActionExtract, ship.checkMount(ActionExtract, "MOUNT_MINING_LASER"))
+	assert.True(t, errors.Is(err, ErrMissingMount))
+
+	ship.Mounts = []models.ShipMount{{Symbol: string(models.MountMiningLaserI)}}
+	assert.NoError(t, ship.prepare(ActionExtract, ship.checkMount(ActionExtract, "MOUNT_MINING_LASER")))
+
+	ship.Cooldown = models.ShipCooldown{
+		RemainingSeconds: 30,
+		Expiration:       time.Now().Add(30 * time.Second).Format(time.RFC3339),
+	}
+	err = ship.prepare(ActionExtract)
+	assert.True(t, errors.Is(err, ErrCooldownActive))
+}
+
+func TestPreflight_Cargo(t *testing.T) {
+	ship := newPreflightShip()
+
+	assert.NoError(t, ship.prepare(ActionSellCargo, ship.checkCargoHeld(ActionSellCargo, models.IronOre, 10)))
+
+	err := ship.prepare(ActionJettison, ship.checkCargoHeld(ActionJettison, models.IronOre, 11))
+	assert.True(t, errors.Is(err, ErrInsufficientCargo))
+
+	err = ship.prepare(ActionPurchaseCargo, ship.checkCargoSpace(ActionPurchaseCargo, 31))
+	assert.True(t, errors.Is(err, ErrInsufficientCargoRoom))
+}
+
+func TestPreflight_NavigateFuel(t *testing.T) {
+	ship := newPreflightShip()
+	ship.Nav.Status = models.NavStatusInOrbit
+
+	err := ship.prepare(ActionNavigate, ship.checkNavigateFuel("X1-TEST-B2"))
+	assert.True(t, errors.Is(err, ErrInsufficientFuel))
+
+	ship.Nav.FlightMode = models.FlightModeDrift
+	assert.NoError(t, ship.prepare(ActionNavigate, ship.checkNavigateFuel("X1-TEST-B2")))
+
+	// Unknown destinations are left to the API
+	ship.Nav.FlightMode = models.FlightModeCruise
+	assert.NoError(t, ship.prepare(ActionNavigate, ship.checkNavigateFuel("X2-OTHER-C3")))
+}
+
+func TestPreflight_InstallMount(t *testing.T) {
+	ship := newPreflightShip()
+
+	err := ship.prepare(ActionInstallMount, ship.checkInstallMount(models.MountSurveyorI))
+	assert.True(t, errors.Is(err, ErrInsufficientCargo))
+
+	ship.Cargo.Inventory = append(ship.Cargo.Inventory, models.Inventory{Symbol: string(models.MountSurveyorII), Units: 1})
+	ship.Reactor.PowerOutput = 1
+	err = ship.prepare(ActionInstallMount, ship.checkInstallMount(models.MountSurveyorII))
+	assert.True(t, errors.Is(err, ErrInsufficientPower))
+
+	ship.Mounts = []models.ShipMount{{Symbol: string(models.MountMiningLaserI)}}
+	err = ship.prepare(ActionInstallMount, ship.checkInstallMount(models.MountSurveyorII))
+	assert.True(t, errors.Is(err, ErrNoFreeMountingPoint))
+}
+
+func TestPreflight_Disabled(t *testing.T) {
+	ship := newPreflightShip()
+	ship.SetPreflight(false)
+
+	assert.NoError(t, ship.prepare(ActionExtract, ship.checkMount(ActionExtract, "MOUNT_MINING_LASER")))
+}
+
+func TestPreflight_ShipMethods(t *testing.T) {
+	var requests atomic.Int32
+	c := newStatusClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+
+	inOrbit := func(s *Ship) { s.Nav.Status = models.NavStatusInOrbit }
+	onCooldown := func(s *Ship) {
+		s.Cooldown = models.ShipCooldown{
+			RemainingSeconds: 30,
+			Expiration:       time.Now().Add(30 * time.Second).Format(time.RFC3339),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		setup  func(*Ship)
+		call   func(*Ship) error
+		action ShipAction
+		want   error
+	}{
+		{
+			name:   "extract while docked",
+			call:   func(s *Ship) error { _, err := s.Extract(); return err },
+			action: ActionExtract,
+			want:   ErrShipNotInOrbit,
+		},
+		{
+			name:   "extract without a mining laser",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, err := s.Extract(); return err },
+			action: ActionExtract,
+			want:   ErrMissingMount,
+		},
+		{
+			name: "extract with a full hold",
+			setup: func(s *Ship) {
+				inOrbit(s)
+				s.Mounts = []models.ShipMount{{Symbol: string(models.MountMiningLaserI)}}
+				s.Cargo.Units = s.Cargo.Capacity
+			},
+			call:   func(s *Ship) error { _, err := s.Extract(); return err },
+			action: ActionExtract,
+			want:   ErrInsufficientCargoRoom,
+		},
+		{
+			name: "extract with a survey on cooldown",
+			setup: func(s *Ship) {
+				inOrbit(s)
+				onCooldown(s)
+			},
+			call:   func(s *Ship) error { _, err := s.ExtractWithSurvey(models.Survey{}); return err },
+			action: ActionExtract,
+			want:   ErrCooldownActive,
+		},
+		{
+			name:   "siphon without a gas siphon",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, err := s.Siphon(); return err },
+			action: ActionSiphon,
+			want:   ErrMissingMount,
+		},
+		{
+			name:   "survey without a surveyor",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, err := s.Survey(); return err },
+			action: ActionSurvey,
+			want:   ErrMissingMount,
+		},
+		{
+			name:   "refine on cooldown",
+			setup:  onCooldown,
+			call:   func(s *Ship) error { _, _, err := s.Refine("IRON"); return err },
+			action: ActionRefine,
+			want:   ErrCooldownActive,
+		},
+		{
+			name:   "sell cargo in orbit",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, _, _, err := s.SellCargo(models.IronOre, 5); return err },
+			action: ActionSellCargo,
+			want:   ErrShipNotDocked,
+		},
+		{
+			name:   "sell more cargo than held",
+			call:   func(s *Ship) error { _, _, _, err := s.SellCargo(models.IronOre, 11); return err },
+			action: ActionSellCargo,
+			want:   ErrInsufficientCargo,
+		},
+		{
+			name:   "purchase more cargo than fits",
+			call:   func(s *Ship) error { _, _, _, err := s.PurchaseCargo(models.IronOre, 31); return err },
+			action: ActionPurchaseCargo,
+			want:   ErrInsufficientCargoRoom,
+		},
+		{
+			name:   "purchase no cargo",
+			call:   func(s *Ship) error { _, _, _, err := s.PurchaseCargo(models.IronOre, 0); return err },
+			action: ActionPurchaseCargo,
+			want:   ErrInvalidUnits,
+		},
+		{
+			name:   "jettison more cargo than held",
+			call:   func(s *Ship) error { _, err := s.Jettison(models.IronOre, 11); return err },
+			action: ActionJettison,
+			want:   ErrInsufficientCargo,
+		},
+		{
+			name:   "transfer cargo not held",
+			call:   func(s *Ship) error { _, err := s.TransferCargo(models.CopperOre, 1, "TEST-2"); return err },
+			action: ActionTransferCargo,
+			want:   ErrInsufficientCargo,
+		},
+		{
+			name:   "navigate while docked",
+			call:   func(s *Ship) error { _, _, _, err := s.Navigate("X1-TEST-B2"); return err },
+			action: ActionNavigate,
+			want:   ErrShipNotInOrbit,
+		},
+		{
+			name:   "navigate without enough fuel",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, _, _, err := s.Navigate("X1-TEST-B2"); return err },
+			action: ActionNavigate,
+			want:   ErrInsufficientFuel,
+		},
+		{
+			name: "navigate in transit",
+			setup: func(s *Ship) {
+				s.Nav.Status = models.NavStatusInTransit
+				s.Nav.Route.Arrival = time.Now().Add(time.Minute).Format(time.RFC3339)
+			},
+			call:   func(s *Ship) error { _, _, _, err := s.Navigate("X1-TEST-B2"); return err },
+			action: ActionNavigate,
+			want:   ErrShipInTransit,
+		},
+		{
+			name:   "warp while docked",
+			call:   func(s *Ship) error { _, _, err := s.Warp("X1-OTHER-A1"); return err },
+			action: ActionWarp,
+			want:   ErrShipNotInOrbit,
+		},
+		{
+			name:   "jump on cooldown",
+			setup:  func(s *Ship) { inOrbit(s); onCooldown(s) },
+			call:   func(s *Ship) error { _, _, _, _, err := s.Jump("X1-OTHER"); return err },
+			action: ActionJump,
+			want:   ErrCooldownActive,
+		},
+		{
+			name:   "refuel in orbit",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, _, _, err := s.Refuel(0, false); return err },
+			action: ActionRefuel,
+			want:   ErrShipNotDocked,
+		},
+		{
+			name:   "negotiate a contract in orbit",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, err := s.NegotiateContract(); return err },
+			action: ActionNegotiate,
+			want:   ErrShipNotDocked,
+		},
+		{
+			name:   "install a mount not in cargo",
+			call:   func(s *Ship) error { _, _, _, _, err := s.InstallMount(models.MountSurveyorI); return err },
+			action: ActionInstallMount,
+			want:   ErrInsufficientCargo,
+		},
+		{
+			name:   "remove a mount in orbit",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, _, _, _, err := s.RemoveMount(models.MountSurveyorI); return err },
+			action: ActionRemoveMount,
+			want:   ErrShipNotDocked,
+		},
+		{
+			name:   "scrap in orbit",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, err := s.ScrapShip(); return err },
+			action: ActionScrap,
+			want:   ErrShipNotDocked,
+		},
+		{
+			name:   "repair in orbit",
+			setup:  inOrbit,
+			call:   func(s *Ship) error { _, _, err := s.RepairShip(); return err },
+			action: ActionRepair,
+			want:   ErrShipNotDocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ship := newPreflightShip()
+			ship.Client = c
+			if tt.setup != nil {
+				tt.setup(ship)
+			}
+
+			err := tt.call(ship)
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tt.want), "got %v", err)
+
+			var preflightErr *PreflightError
+			require.True(t, errors.As(err, &preflightErr))
+			assert.Equal(t, tt.action, preflightErr.Action)
+		})
+	}
+
+	assert.Equal(t, int32(0), requests.Load(), "failed preflight checks send no requests")
+}
@@ -0,0 +1,252 @@
+package entities
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+)
+
+// ResetWatcherOptions configures a ResetWatcher
+type ResetWatcherOptions struct {
+	// Interval between server status polls (default: 10 minutes)
+	Interval time.Duration
+	// WarnBefore is how long before the next reset the warning callbacks run (default: 1 hour)
+	WarnBefore time.Duration
+	// StatePath is a file the last seen reset is persisted to, so resets that happen while
+	// the bot is stopped are still reported. Empty keeps the state in memory only.
+	StatePath string
+}
+
+// DefaultResetWatcherOptions returns the default configuration for a ResetWatcher
+func DefaultResetWatcherOptions() ResetWatcherOptions {
+	return ResetWatcherOptions{
+		Interval:   10 * time.Minute,
+		WarnBefore: time.Hour,
+	}
+}
+
+// ServerReset describes a reset detected from the server status
+type ServerReset struct {
+	// PreviousResetDate is the resetDate seen before the reset
+	PreviousResetDate string
+	// ResetDate is the server's current resetDate
+	ResetDate string
+	// NextReset is when the following reset is scheduled, zero if unknown
+	NextReset time.Time
+}
+
+// resetWatcherState is the state persisted between polls and restarts
+type resetWatcherState struct {
+	ResetDate string    `json:"resetDate"`
+	NextReset time.Time `json:"nextReset"`
+	// WarnedFor is the reset the warning callbacks last ran for
+	WarnedFor time.Time `json:"warnedFor,omitempty"`
+}
+
+// ResetWatcher polls the server status and reports upcoming and past game resets
+// before requests start failing with token version mismatches
+type ResetWatcher struct {
+	client  *client.Client
+	options ResetWatcherOptions
+
+	mu        sync.RWMutex
+	state     resetWatcherState
+	onWarning []func(next time.Time)
+	onReset   []func(ServerReset)
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewResetWatcher creates a watcher, loading any state persisted at options.StatePath
+func NewResetWatcher(c *client.Client, options ResetWatcherOptions) (*ResetWatcher, error) {
+	defaults := DefaultResetWatcherOptions()
+	if options.Interval <= 0 {
+		options.Interval = defaults.Interval
+	}
+	if options.WarnBefore <= 0 {
+		options.WarnBefore = defaults.WarnBefore
+	}
+
+	watcher := &ResetWatcher{
+		client:  c,
+		options: options,
+	}
+
+	if options.StatePath != "" {
+		data, err := os.ReadFile(options.StatePath)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read reset watcher state: %w", err)
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &watcher.state); err != nil {
+				return nil, fmt.Errorf("failed to decode reset watcher state: %w", err)
+			}
+		}
+	}
+
+	return watcher, nil
+}
+
+// OnWarning registers a callback run once per scheduled reset when it is less than
+// WarnBefore away. Callbacks run on the polling goroutine.
+func (w *ResetWatcher) OnWarning(callback func(next time.Time)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onWarning = append(w.onWarning, callback)
+}
+
+// OnReset registers a callback run when a reset is detected. Callbacks run on the polling goroutine.
+func (w *ResetWatcher) OnReset(callback func(ServerReset)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.onReset = append(w.onReset, callback)
+}
+
+// NextReset returns when the next reset is scheduled, and false if it is not known yet
+func (w *ResetWatcher) NextReset() (time.Time, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.state.NextReset, !w.state.NextReset.IsZero()
+}
+
+// TimeUntilReset returns the time remaining until the next reset, or 0 if it is unknown or overdue
+func (w *ResetWatcher) TimeUntilReset() time.Duration {
+	next, ok := w.NextReset()
+	if !ok {
+		return 0
+	}
+	return max(time.Until(next), 0)
+}
+
+// ResetDate returns the server's resetDate at the last poll
+func (w *ResetWatcher) ResetDate() string {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.state.ResetDate
+}
+
+// Start polls immediately and then at every interval until Stop is called or ctx is cancelled
+func (w *ResetWatcher) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+
+		ticker := time.NewTicker(w.options.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.Check(); err != nil {
+				w.client.Logger.Warn("Server status check failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (w *ResetWatcher) Stop() {
+	if w.cancel != nil {
+		w.cancel()
+		<-w.done
+	}
+}
+
+// Check polls the server status once, running the warning and reset callbacks as needed
+func (w *ResetWatcher) Check() error {
+	status, err := fetchServerStatus(w.client)
+	if err != nil {
+		return fmt.Errorf("failed to get server status: %w", err)
+	}
+
+	next, _ := status.NextReset()
+
+	now := time.Now()
+
+	w.mu.Lock()
+	previous := w.state
+
+	// A changed resetDate, or a new schedule after the stored reset time has passed,
+	// means a reset happened since the last poll
+	reset := previous.ResetDate != "" &&
+		(status.ResetDate != previous.ResetDate ||
+			(!previous.NextReset.IsZero() && !next.IsZero() && !next.Equal(previous.NextReset) && now.After(previous.NextReset)))
+
+	w.state.ResetDate = status.ResetDate
+	w.state.NextReset = next
+
+	warn := !next.IsZero() && next.After(now) && next.Sub(now) <= w.options.WarnBefore && !w.state.WarnedFor.Equal(next)
+	if warn {
+		w.state.WarnedFor = next
+	}
+
+	state := w.state
+	onWarning := append([]func(time.Time){}, w.onWarning...)
+	onReset := append([]func(ServerReset){}, w.onReset...)
+	w.mu.Unlock()
+
+	if err := w.persist(state); err != nil {
+		return err
+	}
+
+	if reset {
+		w.client.Logger.Warn("Game reset detected from server status",
+			"previousResetDate", previous.ResetDate,
+			"resetDate", status.ResetDate)
+
+		event := ServerReset{
+			PreviousResetDate: previous.ResetDate,
+			ResetDate:         status.ResetDate,
+			NextReset:         next,
+		}
+		for _, callback := range onReset {
+			callback(event)
+		}
+	}
+
+	if warn {
+		w.client.Logger.Info("Game reset approaching", "nextReset", next, "remaining", time.Until(next).Round(time.Second))
+		for _, callback := range onWarning {
+			callback(next)
+		}
+	}
+
+	return nil
+}
+
+// persist writes the state to the configured state file, if any
+func (w *ResetWatcher) persist(state resetWatcherState) error {
+	if w.options.StatePath == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp := w.options.StatePath + ".tmp"
+	if err := os.WriteFile(temp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write reset watcher state: %w", err)
+	}
+	if err := os.Rename(temp, w.options.StatePath); err != nil {
+		return fmt.Errorf("failed to write reset watcher state: %w", err)
+	}
+	return nil
+}
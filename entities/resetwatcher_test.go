@@ -0,0 +1,119 @@
+package entities
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusServer serves GET / with a resetDate and next reset that tests can change
+type statusServer struct {
+	mu        sync.Mutex
+	resetDate string
+	next      time.Time
+	requests  int
+}
+
+func (s *statusServer) set(resetDate string, next time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.resetDate = resetDate
+	s.next = next
+}
+
+func (s *statusServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *statusServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"data":{"status":"ok","version":"v2.3.0","resetDate":%q,"serverResets":{"next":%q,"frequency":"weekly"},`+
+		`"leaderboards":{"mostCredits":[{"agentSymbol":"LEADER","credits":9000000},{"agentSymbol":"TEST","credits":%d}],`+
+		`"mostSubmittedCharts":[{"agentSymbol":"TEST","chartCount":120}]},`+
+		`"announcements":[{"title":"Reset","body":"Weekly reset"}]}}`,
+		s.resetDate, s.next.UTC().Format(time.RFC3339), 100000*s.requests)
+}
+
+func newStatusClient(t *testing.T, handler http.Handler) *client.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	store := client.NewMemoryTokenStore()
+	require.NoError(t, store.Set("TEST", client.TokenEntry{Token: "token"}))
+
+	options := client.DefaultClientOptions()
+	options.BaseURL = server.URL
+	options.Symbol = "TEST"
+	options.Faction = "COSMIC"
+	options.TokenStore = store
+
+	c, err := client.NewClient(options)
+	require.NoError(t, err)
+	t.Cleanup(func() { c.Close(t.Context()) })
+	return c
+}
+
+func TestResetWatcher(t *testing.T) {
+	status := &statusServer{}
+	next := time.Now().Add(2 * time.Hour).Truncate(time.Second)
+	status.set("2026-10-11", next)
+
+	c := newStatusClient(t, status)
+	statePath := filepath.Join(t.TempDir(), "reset.json")
+
+	watcher, err := NewResetWatcher(c, ResetWatcherOptions{WarnBefore: time.Hour, StatePath: statePath})
+	require.NoError(t, err)
+
+	var warnings []time.Time
+	var resets []ServerReset
+	watcher.OnWarning(func(next time.Time) { warnings = append(warnings, next) })
+	watcher.OnReset(func(reset ServerReset) { resets = append(resets, reset) })
+
+	// The first poll only records the current reset
+	require.NoError(t, watcher.Check())
+	assert.Empty(t, resets)
+	assert.Empty(t, warnings)
+	assert.Equal(t, "2026-10-11", watcher.ResetDate())
+	recorded, ok := watcher.NextReset()
+	assert.True(t, ok)
+	assert.True(t, next.Equal(recorded))
+	assert.InDelta(t, 2*time.Hour, watcher.TimeUntilReset(), float64(time.Minute))
+
+	// Within WarnBefore the warning runs once
+	soon := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	status.set("2026-10-11", soon)
+	require.NoError(t, watcher.Check())
+	require.NoError(t, watcher.Check())
+	require.Len(t, warnings, 1)
+	assert.True(t, soon.Equal(warnings[0]))
+
+	// A watcher restored from the state file reports the reset that happened meanwhile
+	status.set("2026-10-18", time.Now().Add(7*24*time.Hour))
+	restored, err := NewResetWatcher(c, ResetWatcherOptions{WarnBefore: time.Hour, StatePath: statePath})
+	require.NoError(t, err)
+	restored.OnReset(func(reset ServerReset) { resets = append(resets, reset) })
+
+	require.NoError(t, restored.Check())
+	require.Len(t, resets, 1)
+	assert.Equal(t, "2026-10-11", resets[0].PreviousResetDate)
+	assert.Equal(t, "2026-10-18", resets[0].ResetDate)
+
+	require.NoError(t, restored.Check())
+	assert.Len(t, resets, 1)
+}
@@ -0,0 +1,287 @@
+package entities
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+	"github.com/jjkirkpatrick/spacetraders-client/internal/api"
+	"github.com/jjkirkpatrick/spacetraders-client/models"
+)
+
+// ServerStatusTTL is the longest a server status is cached for. The cached status
+// always expires at the next reset, when everything it reports changes.
+const ServerStatusTTL = 15 * time.Minute
+
+const serverStatusCacheKey = "server:status"
+
+// ServerStatus is the status of the game server
+type ServerStatus struct {
+	models.ServerStatusResponse
+	Client *client.Client
+	// FetchedAt is when the status was fetched from the API
+	FetchedAt time.Time
+}
+
+// cachedServerStatus is the server status kept in CacheClient, without the client, so
+// that it can be included in cache snapshots
+type cachedServerStatus struct {
+	Status    models.ServerStatusResponse `json:"status"`
+	FetchedAt time.Time                   `json:"fetchedAt"`
+}
+
+// GetServerStatus returns the server status, version, stats, reset schedule, announcements
+// and leaderboards. The status is cached for ServerStatusTTL or until the next reset.
+func GetServerStatus(c *client.Client) (*ServerStatus, error) {
+	if cached, found := c.CacheClient.Get(serverStatusCacheKey); found {
+		var status cachedServerStatus
+		switch cached := cached.(type) {
+		case cachedServerStatus:
+			status = cached
+		case json.RawMessage:
+			// Restored from a cache snapshot, which does not record the type
+			if err := json.Unmarshal(cached, &status); err != nil {
+				status = cachedServerStatus{}
+			}
+		}
+		if !status.FetchedAt.IsZero() {
+			return &ServerStatus{ServerStatusResponse: status.Status, Client: c, FetchedAt: status.FetchedAt}, nil
+		}
+	}
+
+	status, err := fetchServerStatus(c)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := ServerStatusTTL
+	if untilReset := status.TimeUntilReset(); untilReset > 0 && untilReset < ttl {
+		ttl = untilReset
+	}
+	c.CacheClient.Set(serverStatusCacheKey, cachedServerStatus{
+		Status:    status.ServerStatusResponse,
+		FetchedAt: status.FetchedAt,
+	}, ttl)
+
+	return status, nil
+}
+
+// fetchServerStatus fetches the server status from the API, bypassing the cache
+func fetchServerStatus(c *client.Client) (*ServerStatus, error) {
+	status, err := api.GetServerStatus(c.Get)
+	if err != nil {
+		return nil, err.AsError()
+	}
+
+	return &ServerStatus{
+		ServerStatusResponse: *status,
+		Client:               c,
+		FetchedAt:            time.Now(),
+	}, nil
+}
+
+// NextReset returns when the next reset is scheduled, and false if the schedule is unknown
+func (s *ServerStatus) NextReset() (time.Time, bool) {
+	next, err := time.Parse(time.RFC3339, s.ServerResets.Next)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// TimeUntilReset returns the time remaining until the next reset, or 0 if it is unknown or overdue
+func (s *ServerStatus) TimeUntilReset() time.Duration {
+	next, ok := s.NextReset()
+	if !ok {
+		return 0
+	}
+	return max(time.Until(next), 0)
+}
+
+// LeaderboardSnapshot is the state of the leaderboards at a point in time
+type LeaderboardSnapshot struct {
+	Time                time.Time              `json:"time"`
+	ResetDate           string                 `json:"resetDate"`
+	MostCredits         []models.CreditsLeader `json:"mostCredits"`
+	MostSubmittedCharts []models.ChartsLeader  `json:"mostSubmittedCharts"`
+}
+
+// LeaderboardPoint is an agent's standing in a single snapshot
+type LeaderboardPoint struct {
+	Time time.Time
+	// Rank is the 1-based position on the leaderboard
+	Rank  int
+	Value int64
+}
+
+// LeaderboardRecorderOptions configures a LeaderboardRecorder
+type LeaderboardRecorderOptions struct {
+	// Interval between snapshots when started (default: 1 hour)
+	Interval time.Duration
+	// Path is a JSONL file snapshots are appended to and loaded from on creation.
+	// Empty keeps snapshots in memory only.
+	Path string
+}
+
+// LeaderboardRecorder records leaderboard snapshots over time
+type LeaderboardRecorder struct {
+	client  *client.Client
+	options LeaderboardRecorderOptions
+
+	mu        sync.RWMutex
+	snapshots []LeaderboardSnapshot
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewLeaderboardRecorder creates a recorder, loading any snapshots already stored at options.Path
+func NewLeaderboardRecorder(c *client.Client, options LeaderboardRecorderOptions) (*LeaderboardRecorder, error) {
+	if options.Interval <= 0 {
+		options.Interval = time.Hour
+	}
+
+	recorder := &LeaderboardRecorder{
+		client:  c,
+		options: options,
+	}
+
+	if options.Path != "" {
+		file, err := os.Open(options.Path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to open leaderboard history: %w", err)
+		}
+		if err == nil {
+			defer file.Close()
+
+			scanner := bufio.NewScanner(file)
+			for scanner.Scan() {
+				var snapshot LeaderboardSnapshot
+				if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+					return nil, fmt.Errorf("failed to decode leaderboard snapshot: %w", err)
+				}
+				recorder.snapshots = append(recorder.snapshots, snapshot)
+			}
+			if err := scanner.Err(); err != nil {
+				return nil, fmt.Errorf("failed to read leaderboard history: %w", err)
+			}
+		}
+	}
+
+	return recorder, nil
+}
+
+// Record fetches the current leaderboards and stores a snapshot
+func (r *LeaderboardRecorder) Record() (*LeaderboardSnapshot, error) {
+	status, err := fetchServerStatus(r.client)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := LeaderboardSnapshot{
+		Time:                status.FetchedAt.UTC(),
+		ResetDate:           status.ResetDate,
+		MostCredits:         status.Leaderboards.MostCredits,
+		MostSubmittedCharts: status.Leaderboards.MostSubmittedCharts,
+	}
+
+	if r.options.Path != "" {
+		file, err := os.OpenFile(r.options.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open leaderboard history: %w", err)
+		}
+		defer file.Close()
+
+		if err := json.NewEncoder(file).Encode(snapshot); err != nil {
+			return nil, fmt.Errorf("failed to write leaderboard snapshot: %w", err)
+		}
+	}
+
+	r.mu.Lock()
+	r.snapshots = append(r.snapshots, snapshot)
+	r.mu.Unlock()
+
+	return &snapshot, nil
+}
+
+// Snapshots returns a copy of the recorded snapshots, oldest first
+func (r *LeaderboardRecorder) Snapshots() []LeaderboardSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots := make([]LeaderboardSnapshot, len(r.snapshots))
+	copy(snapshots, r.snapshots)
+	return snapshots
+}
+
+// CreditsHistory returns the agent's credits and rank in every snapshot it appears in
+func (r *LeaderboardRecorder) CreditsHistory(agentSymbol string) []LeaderboardPoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var points []LeaderboardPoint
+	for _, snapshot := range r.snapshots {
+		for i, leader := range snapshot.MostCredits {
+			if leader.AgentSymbol == agentSymbol {
+				points = append(points, LeaderboardPoint{Time: snapshot.Time, Rank: i + 1, Value: leader.Credits})
+				break
+			}
+		}
+	}
+	return points
+}
+
+// ChartsHistory returns the agent's chart count and rank in every snapshot it appears in
+func (r *LeaderboardRecorder) ChartsHistory(agentSymbol string) []LeaderboardPoint {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var points []LeaderboardPoint
+	for _, snapshot := range r.snapshots {
+		for i, leader := range snapshot.MostSubmittedCharts {
+			if leader.AgentSymbol == agentSymbol {
+				points = append(points, LeaderboardPoint{Time: snapshot.Time, Rank: i + 1, Value: int64(leader.ChartCount)})
+				break
+			}
+		}
+	}
+	return points
+}
+
+// Start records a snapshot immediately and then at every interval until Stop is called or ctx is cancelled
+func (r *LeaderboardRecorder) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+	r.done = make(chan struct{})
+
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(r.options.Interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := r.Record(); err != nil {
+				r.client.Logger.Warn("Leaderboard snapshot failed", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops recording snapshots
+func (r *LeaderboardRecorder) Stop() {
+	if r.cancel != nil {
+		r.cancel()
+		<-r.done
+	}
+}
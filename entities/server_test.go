@@ -0,0 +1,82 @@
+package entities
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetServerStatus(t *testing.T) {
+	status := &statusServer{}
+	status.set("2026-10-18", time.Now().Add(2*time.Hour))
+	c := newStatusClient(t, status)
+
+	server, err := GetServerStatus(c)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.3.0", server.Version)
+	assert.Equal(t, "weekly", server.ServerResets.Frequency)
+	require.Len(t, server.Announcements, 1)
+	assert.Equal(t, "Reset", server.Announcements[0].Title)
+	require.Len(t, server.Leaderboards.MostCredits, 2)
+	assert.Equal(t, "LEADER", server.Leaderboards.MostCredits[0].AgentSymbol)
+	assert.InDelta(t, 2*time.Hour, server.TimeUntilReset(), float64(time.Minute))
+
+	// Served from the cache until the TTL or the next reset
+	_, err = GetServerStatus(c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.count())
+}
+
+func TestLeaderboardRecorder(t *testing.T) {
+	status := &statusServer{}
+	status.set("2026-10-18", time.Now().Add(2*time.Hour))
+	c := newStatusClient(t, status)
+
+	path := filepath.Join(t.TempDir(), "leaderboards.jsonl")
+	recorder, err := NewLeaderboardRecorder(c, LeaderboardRecorderOptions{Path: path})
+	require.NoError(t, err)
+
+	_, err = recorder.Record()
+	require.NoError(t, err)
+	_, err = recorder.Record()
+	require.NoError(t, err)
+
+	history := recorder.CreditsHistory("TEST")
+	require.Len(t, history, 2)
+	assert.Equal(t, 2, history[0].Rank)
+	assert.Equal(t, int64(100000), history[0].Value)
+	assert.Equal(t, int64(200000), history[1].Value)
+	assert.Len(t, recorder.ChartsHistory("TEST"), 2)
+
+	// Snapshots are reloaded from the history file
+	reloaded, err := NewLeaderboardRecorder(c, LeaderboardRecorderOptions{Path: path})
+	require.NoError(t, err)
+	assert.Len(t, reloaded.Snapshots(), 2)
+	assert.Equal(t, "2026-10-18", reloaded.Snapshots()[1].ResetDate)
+}
+
+func TestGetServerStatus_Snapshot(t *testing.T) {
+	status := &statusServer{}
+	status.set("2026-10-18", time.Now().Add(2*time.Hour))
+	c := newStatusClient(t, status)
+
+	fetched, err := GetServerStatus(c)
+	require.NoError(t, err)
+
+	// The cached status can be dumped and restored
+	var snapshot bytes.Buffer
+	require.NoError(t, c.CacheClient.Dump(&snapshot))
+	c.CacheClient.Clear()
+	require.NoError(t, c.CacheClient.Restore(&snapshot))
+
+	restored, err := GetServerStatus(c)
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.count())
+	assert.Same(t, c, restored.Client)
+	assert.Equal(t, fetched.Version, restored.Version)
+	assert.True(t, fetched.FetchedAt.Equal(restored.FetchedAt))
+}
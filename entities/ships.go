@@ -3,12 +3,14 @@ package entities
 import (
 	"container/heap"
 	"context"
+	"fmt"
+	"iter"
+	"log/slog"
 	"math"
 
 	"github.com/jjkirkpatrick/spacetraders-client/client"
 	"github.com/jjkirkpatrick/spacetraders-client/internal/api"
 	"github.com/jjkirkpatrick/spacetraders-client/models"
-	"github.com/phuslu/log"
 )
 
 type Ship struct {
@@ -16,6 +18,9 @@ type Ship struct {
 	Client *client.Client
 	Graph  models.Graph
 	ctx    context.Context // Context for metric labels
+
+	preflight    bool // Validate actions locally before sending them
+	autoPosition bool // Dock or orbit as each action requires
 }
 
 // SetContext sets the context for all subsequent API calls on this ship.
@@ -24,6 +29,15 @@ func (s *Ship) SetContext(ctx context.Context) {
 	s.ctx = ctx
 }
 
+// logger returns the client's logger with the ship's symbol attached
+func (s *Ship) logger() *slog.Logger {
+	logger := slog.Default()
+	if s.Client != nil && s.Client.Logger != nil {
+		logger = s.Client.Logger
+	}
+	return logger.With("ship", s.Symbol)
+}
+
 // getFunc returns a GetFunc that uses context if available
 func (s *Ship) getFunc() api.GetFunc {
 	if s.ctx != nil {
@@ -55,11 +69,22 @@ func (s *Ship) patchFunc() api.PatchFunc {
 }
 
 func ListShips(c *client.Client) ([]*Ship, error) {
-	fetchFunc := func(meta models.Meta) ([]*Ship, models.Meta, error) {
+	return client.NewPaginator[*Ship](shipPages(c, c.Get)).WithRetryPolicy(c.RetryPolicy()).FetchAllPages()
+}
+
+// IterShips streams ships in page order, fetching the following pages while earlier ones are
+// consumed. Breaking out of the loop or cancelling ctx stops fetching.
+func IterShips(ctx context.Context, c *client.Client) iter.Seq2[*Ship, error] {
+	return client.NewPaginator[*Ship](shipPages(c, contextGet(ctx, c))).WithRetryPolicy(c.RetryPolicy()).All(ctx)
+}
+
+// shipPages returns a function fetching one page of ships with get
+func shipPages(c *client.Client, get api.GetFunc) func(models.Meta) ([]*Ship, models.Meta, error) {
+	return func(meta models.Meta) ([]*Ship, models.Meta, error) {
 		metaPtr := &meta
 
 		// Check if ships are in cache
-		ships, metaPtr, err := api.ListShips(c.Get, metaPtr)
+		ships, metaPtr, err := api.ListShips(get, metaPtr)
 
 		var convertedShips []*Ship
 		for _, modelShip := range ships {
@@ -91,7 +116,6 @@ func ListShips(c *client.Client) ([]*Ship, error) {
 			return convertedShips, defaultMeta, nil
 		}
 	}
-	return client.NewPaginator[*Ship](fetchFunc).FetchAllPages()
 }
 
 func GetShip(c *client.Client, symbol string) (*Ship, error) {
@@ -136,8 +160,6 @@ func PurchaseShip(c *client.Client, shipType string, waypoint string) (*models.A
 	}
 	shipEntity.Graph = *graph
 
-	c.CacheClient.Delete("all_ships")
-
 	return &response.Data.Agent, shipEntity, &response.Data.Transaction, nil
 }
 
@@ -147,6 +169,14 @@ func (s *Ship) Orbit() (*models.ShipNav, error) {
 		return &s.Nav, nil
 	}
 
+	if err := s.prepare(ActionOrbit); err != nil {
+		return nil, err
+	}
+	// Waiting for an in-transit ship to arrive may have left it in orbit already
+	if s.Nav.Status == models.NavStatusInOrbit {
+		return &s.Nav, nil
+	}
+
 	nav, err := api.OrbitShip(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, err.AsError()
@@ -163,6 +193,10 @@ func (s *Ship) Dock() (*models.ShipNav, error) {
 		return &s.Nav, nil
 	}
 
+	if err := s.prepare(ActionDock); err != nil {
+		return nil, err
+	}
+
 	nav, err := api.DockShip(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, err.AsError()
@@ -185,6 +219,10 @@ func (s *Ship) FetchCargo() (*models.Cargo, error) {
 }
 
 func (s *Ship) Refine(produce string) (*models.Produced, *models.Consumed, error) {
+	if err := s.prepare(ActionRefine); err != nil {
+		return nil, nil, err
+	}
+
 	refineRequest := &models.RefineRequest{
 		Produce: produce,
 	}
@@ -221,6 +259,10 @@ func (s *Ship) FetchCooldown() (*models.ShipCooldown, error) {
 }
 
 func (s *Ship) Survey() ([]models.Survey, error) {
+	if err := s.prepare(ActionSurvey, s.checkMount(ActionSurvey, "MOUNT_SURVEYOR")); err != nil {
+		return nil, err
+	}
+
 	response, err := api.CreateSurvey(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, err.AsError()
@@ -232,6 +274,10 @@ func (s *Ship) Survey() ([]models.Survey, error) {
 }
 
 func (s *Ship) Extract() (*models.Extraction, error) {
+	if err := s.prepare(ActionExtract, s.checkMount(ActionExtract, "MOUNT_MINING_LASER"), s.checkCargoSpace(ActionExtract, 1)); err != nil {
+		return nil, err
+	}
+
 	response, err := api.ExtractResources(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, err.AsError()
@@ -244,6 +290,10 @@ func (s *Ship) Extract() (*models.Extraction, error) {
 }
 
 func (s *Ship) Siphon() (*models.Extraction, error) {
+	if err := s.prepare(ActionSiphon, s.checkMount(ActionSiphon, "MOUNT_GAS_SIPHON"), s.checkCargoSpace(ActionSiphon, 1)); err != nil {
+		return nil, err
+	}
+
 	response, err := api.SiphonResources(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, err.AsError()
@@ -256,6 +306,10 @@ func (s *Ship) Siphon() (*models.Extraction, error) {
 }
 
 func (s *Ship) ExtractWithSurvey(survey models.Survey) (*models.Extraction, error) {
+	if err := s.prepare(ActionExtract, s.checkMount(ActionExtract, "MOUNT_MINING_LASER"), s.checkCargoSpace(ActionExtract, 1)); err != nil {
+		return nil, err
+	}
+
 	extractWithSurveyRequest := &models.ExtractWithSurveyRequest{
 		Signature:  survey.Signature,
 		Symbol:     survey.Symbol,
@@ -276,6 +330,10 @@ func (s *Ship) ExtractWithSurvey(survey models.Survey) (*models.Extraction, erro
 }
 
 func (s *Ship) Jettison(goodSymbol models.GoodSymbol, units int) (*models.Cargo, error) {
+	if err := s.prepare(ActionJettison, s.checkCargoHeld(ActionJettison, goodSymbol, units)); err != nil {
+		return nil, err
+	}
+
 	jettisonRequest := &models.JettisonRequest{
 		Symbol: goodSymbol,
 		Units:  units,
@@ -292,6 +350,10 @@ func (s *Ship) Jettison(goodSymbol models.GoodSymbol, units int) (*models.Cargo,
 }
 
 func (s *Ship) Jump(systemSymbol string) (*models.ShipNav, *models.ShipCooldown, *models.Transaction, *models.Agent, error) {
+	if err := s.prepare(ActionJump); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	jumpRequest := &models.JumpShipRequest{
 		WaypointSymbol: systemSymbol,
 	}
@@ -308,6 +370,10 @@ func (s *Ship) Jump(systemSymbol string) (*models.ShipNav, *models.ShipCooldown,
 }
 
 func (s *Ship) Navigate(waypointSymbol string) (*models.FuelDetails, *models.ShipNav, []models.Event, error) {
+	if err := s.prepare(ActionNavigate, s.checkNavigateFuel(waypointSymbol)); err != nil {
+		return nil, nil, nil, err
+	}
+
 	navigateRequest := &models.NavigateRequest{
 		WaypointSymbol: waypointSymbol,
 	}
@@ -352,6 +418,10 @@ func (s *Ship) FetchNavigationStatus() (*models.ShipNav, error) {
 }
 
 func (s *Ship) Warp(waypointSymbol string) (*models.FuelDetails, *models.ShipNav, error) {
+	if err := s.prepare(ActionWarp); err != nil {
+		return nil, nil, err
+	}
+
 	warpRequest := &models.WarpRequest{
 		WaypointSymbol: waypointSymbol,
 	}
@@ -368,6 +438,10 @@ func (s *Ship) Warp(waypointSymbol string) (*models.FuelDetails, *models.ShipNav
 }
 
 func (s *Ship) SellCargo(goodSymbol models.GoodSymbol, units int) (*models.Agent, *models.Cargo, *models.Transaction, error) {
+	if err := s.prepare(ActionSellCargo, s.checkCargoHeld(ActionSellCargo, goodSymbol, units)); err != nil {
+		return nil, nil, nil, err
+	}
+
 	sellRequest := &models.SellCargoRequest{
 		Symbol: goodSymbol,
 		Units:  units,
@@ -406,6 +480,10 @@ func (s *Ship) ScanWaypoints() (*models.ShipCooldown, []models.Waypoint, error)
 }
 
 func (s *Ship) Refuel(amount int, fromCargo bool) (*models.Agent, *models.FuelDetails, *models.Transaction, error) {
+	if err := s.prepare(ActionRefuel); err != nil {
+		return nil, nil, nil, err
+	}
+
 	refuelRequest := &models.RefuelShipRequest{
 		FromCargo: fromCargo,
 	}
@@ -417,7 +495,7 @@ func (s *Ship) Refuel(amount int, fromCargo bool) (*models.Agent, *models.FuelDe
 
 	response, err := api.RefuelShip(s.postFunc(), s.Symbol, refuelRequest)
 	if err != nil {
-		log.Error().Msgf("Error refueling ship %s: %v", s.Symbol, err.Data)
+		s.logger().Error("Failed to refuel ship", "error", err.Message, "data", err.Data)
 		return nil, nil, nil, err.AsError()
 	}
 
@@ -427,6 +505,10 @@ func (s *Ship) Refuel(amount int, fromCargo bool) (*models.Agent, *models.FuelDe
 }
 
 func (s *Ship) PurchaseCargo(goodSymbol models.GoodSymbol, units int) (*models.Agent, *models.Cargo, *models.Transaction, error) {
+	if err := s.prepare(ActionPurchaseCargo, s.checkUnits(ActionPurchaseCargo, units), s.checkCargoSpace(ActionPurchaseCargo, units)); err != nil {
+		return nil, nil, nil, err
+	}
+
 	purchaseRequest := &models.PurchaseCargoRequest{
 		Symbol: goodSymbol,
 		Units:  units,
@@ -443,6 +525,10 @@ func (s *Ship) PurchaseCargo(goodSymbol models.GoodSymbol, units int) (*models.A
 }
 
 func (s *Ship) TransferCargo(goodSymbol models.GoodSymbol, units int, shipSymbol string) (*models.Cargo, error) {
+	if err := s.prepare(ActionTransferCargo, s.checkCargoHeld(ActionTransferCargo, goodSymbol, units)); err != nil {
+		return nil, err
+	}
+
 	transferRequest := &models.TransferCargoRequest{
 		TradeSymbol: goodSymbol,
 		Units:       units,
@@ -460,6 +546,9 @@ func (s *Ship) TransferCargo(goodSymbol models.GoodSymbol, units int, shipSymbol
 }
 
 func (s *Ship) NegotiateContract() (*models.Contract, error) {
+	if err := s.prepare(ActionNegotiate); err != nil {
+		return nil, err
+	}
 
 	response, err := api.NegotiateContract(s.postFunc(), s.Symbol)
 	if err != nil {
@@ -479,6 +568,10 @@ func (s *Ship) GetMounts() ([]models.ShipMount, error) {
 }
 
 func (s *Ship) InstallMount(mountSymbol models.MountSymbol) (*models.Agent, []models.ShipMount, *models.Cargo, *models.Transaction, error) {
+	if err := s.prepare(ActionInstallMount, s.checkInstallMount(mountSymbol)); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	installRequest := &models.InstallMountRequest{
 		Symbol: mountSymbol,
 	}
@@ -494,6 +587,10 @@ func (s *Ship) InstallMount(mountSymbol models.MountSymbol) (*models.Agent, []mo
 }
 
 func (s *Ship) RemoveMount(mountSymbol models.MountSymbol) (*models.Agent, []models.ShipMount, *models.Cargo, *models.Transaction, error) {
+	if err := s.prepare(ActionRemoveMount); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
 	removeRequest := &models.RemoveMountRequest{
 		Symbol: mountSymbol,
 	}
@@ -518,13 +615,15 @@ func (s *Ship) GetScrapPrice() (*models.Transaction, error) {
 }
 
 func (s *Ship) ScrapShip() (*models.Transaction, error) {
+	if err := s.prepare(ActionScrap); err != nil {
+		return nil, err
+	}
+
 	response, err := api.ScrapShip(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, err.AsError()
 	}
 
-	s.Client.CacheClient.Delete("all_ships")
-
 	return &response.Data.Transaction, nil
 }
 
@@ -538,6 +637,10 @@ func (s *Ship) GetRepairPrice() (*models.Transaction, error) {
 }
 
 func (s *Ship) RepairShip() (*models.Ship, *models.Transaction, error) {
+	if err := s.prepare(ActionRepair); err != nil {
+		return nil, nil, err
+	}
+
 	response, err := api.RepairShip(s.postFunc(), s.Symbol)
 	if err != nil {
 		return nil, nil, err.AsError()
@@ -549,7 +652,7 @@ func (s *Ship) RepairShip() (*models.Ship, *models.Transaction, error) {
 }
 
 func (s *Ship) GetRouteToDestination(destination string) (*models.PathfindingRoute, error) {
-	log.Debug().Msgf("Getting route for ship %s", s.Symbol)
+	s.logger().Debug("Getting route for ship", "destination", destination)
 
 	// Find the optimal route using Dijkstra's algorithm
 	steps, totalTime := s.findOptimalRoute(destination)
@@ -557,25 +660,27 @@ func (s *Ship) GetRouteToDestination(destination string) (*models.PathfindingRou
 }
 
 func (s *Ship) buildGraph() (*models.Graph, error) {
-	log.Trace().Msgf("Building graph for ship %s", s.Symbol)
-
-	// Attempt to retrieve the graph from cache first
-	cachedGraph, found := s.Client.CacheClient.Get(s.Nav.SystemSymbol)
-	if found {
-		graph, ok := cachedGraph.(models.Graph)
-		if ok {
-			s.Graph = graph
-			return &graph, nil
-		}
+	s.logger().Debug("Building graph for ship", "system", s.Nav.SystemSymbol)
+
+	// Travel times depend on the engine speed, so graphs are kept per speed
+	graph, err := cachedStatic(s.Client, fmt.Sprintf("graphs:%s_%d", s.Nav.SystemSymbol, s.Engine.Speed), s.computeGraph)
+	if err != nil {
+		return nil, err
 	}
 
-	// Retrieve the system and waypoints from cache or API
-	system, err := s.getSystemFromCache()
+	s.Graph = graph
+	return &graph, nil
+}
+
+// computeGraph builds the graph of travel between every waypoint in the ship's system
+func (s *Ship) computeGraph() (models.Graph, error) {
+	// Systems and waypoints are answered from the client's response cache once fetched
+	system, err := GetSystem(s.Client, s.Nav.SystemSymbol)
 	if err != nil {
 		return nil, err
 	}
 
-	allWaypoints, err := s.getWaypointsFromCache(system)
+	allWaypoints, _, err := system.ListWaypoints("", "")
 	if err != nil {
 		return nil, err
 	}
@@ -631,46 +736,11 @@ func (s *Ship) buildGraph() (*models.Graph, error) {
 		}
 	}
 
-	s.Graph = graph
-	// Cache the newly built graph
-	s.Client.CacheClient.Set(s.Nav.SystemSymbol, graph, 0)
-
-	return &graph, nil
+	return graph, nil
 }
 
 // Helper functions
 
-func (s *Ship) getSystemFromCache() (*System, error) {
-	cachedSystem, found := s.Client.CacheClient.Get("system_" + s.Nav.SystemSymbol)
-	if found {
-		system, _ := cachedSystem.(*System)
-		return system, nil
-	}
-
-	system, err := GetSystem(s.Client, s.Nav.SystemSymbol)
-	if err != nil {
-		return nil, err
-	}
-	s.Client.CacheClient.Set("system_"+s.Nav.SystemSymbol, system, 0)
-
-	return system, nil
-}
-
-func (s *Ship) getWaypointsFromCache(system *System) ([]*models.Waypoint, error) {
-	cachedWaypoints, found := s.Client.CacheClient.Get("waypoints_" + s.Nav.SystemSymbol)
-	if found {
-		allWaypoints, _ := cachedWaypoints.([]*models.Waypoint)
-		return allWaypoints, nil
-	}
-	allWaypoints, _, err := system.ListWaypoints("", "")
-	if err != nil {
-		return nil, err
-	}
-	s.Client.CacheClient.Set("waypoints_"+s.Nav.SystemSymbol, allWaypoints, 0)
-
-	return allWaypoints, nil
-}
-
 func (s *Ship) CalculateFuelRequired(distance float64, flightMode models.FlightMode) int {
 	var fuel float64
 	switch flightMode {
@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"encoding/json"
+
+	"github.com/jjkirkpatrick/spacetraders-client/cache"
+	"github.com/jjkirkpatrick/spacetraders-client/client"
+)
+
+// cachedStatic returns static universe data, which does not change within a reset, from
+// CacheClient or the client's cache directory. Missing data is fetched and stored in both.
+func cachedStatic[T any](c *client.Client, key string, fetch func() (T, error)) (T, error) {
+	if cached, found := c.CacheClient.Get(key); found {
+		switch cached := cached.(type) {
+		case T:
+			return cached, nil
+		case json.RawMessage:
+			// Restored from a cache snapshot, which does not record the type
+			var value T
+			if err := json.Unmarshal(cached, &value); err == nil {
+				c.CacheClient.Set(key, value, cache.NoExpiration)
+				return value, nil
+			}
+		}
+	}
+
+	var value T
+	if !c.LoadStatic(key, &value) {
+		fetched, err := fetch()
+		if err != nil {
+			return value, err
+		}
+		value = fetched
+		c.SaveStatic(key, value)
+	}
+
+	c.CacheClient.Set(key, value, cache.NoExpiration)
+	return value, nil
+}
@@ -2,6 +2,7 @@ package entities
 
 import (
 	"context"
+	"iter"
 	"math"
 
 	"github.com/jjkirkpatrick/spacetraders-client/client"
@@ -38,10 +39,59 @@ func (s *System) postFunc() api.PostFunc {
 }
 
 func ListSystems(c *client.Client) ([]*System, error) {
-	fetchFunc := func(meta models.Meta) ([]*System, models.Meta, error) {
+	return client.NewPaginator[*System](systemPages(c, c.Get)).WithRetryPolicy(c.RetryPolicy()).FetchAllPages()
+}
+
+// IterSystems streams systems in page order, fetching the following pages while earlier ones are
+// consumed. Breaking out of the loop or cancelling ctx stops fetching.
+func IterSystems(ctx context.Context, c *client.Client) iter.Seq2[*System, error] {
+	return client.NewPaginator[*System](systemPages(c, contextGet(ctx, c))).WithRetryPolicy(c.RetryPolicy()).All(ctx)
+}
+
+// ListSystemsWithCheckpoint fetches all systems like ListSystems, recording each completed
+// page in store. If an earlier crawl was interrupted, only the missing pages are fetched.
+// Crawls are keyed by the server's reset date, so a reset starts a fresh crawl.
+func ListSystemsWithCheckpoint(ctx context.Context, c *client.Client, store client.CheckpointStore) ([]*System, error) {
+	key := "systems"
+	if status, err := GetServerStatus(c); err == nil && status.ResetDate != "" {
+		key += "-" + status.ResetDate
+	}
+
+	paginator := client.NewPaginator(modelSystemPages(contextGet(ctx, c))).
+		WithRetryPolicy(c.RetryPolicy()).
+		WithCheckpoint(store, key)
+
+	var systems []*System
+	for system, err := range paginator.All(ctx) {
+		if err != nil {
+			return systems, err
+		}
+		systems = append(systems, &System{System: *system, Client: c})
+	}
+	return systems, nil
+}
+
+// modelSystemPages returns a function fetching one page of systems with get. Unlike
+// systemPages, the systems hold no client, so they can be saved in a checkpoint.
+func modelSystemPages(get api.GetFunc) func(models.Meta) ([]*models.System, models.Meta, error) {
+	return func(meta models.Meta) ([]*models.System, models.Meta, error) {
+		systems, metaPtr, err := api.ListSystems(get, &meta)
+		if err != nil {
+			return nil, meta, err.AsError()
+		}
+		if metaPtr == nil {
+			return systems, models.Meta{Page: 1, Limit: 20, Total: 0}, nil
+		}
+		return systems, *metaPtr, nil
+	}
+}
+
+// systemPages returns a function fetching one page of systems with get
+func systemPages(c *client.Client, get api.GetFunc) func(models.Meta) ([]*System, models.Meta, error) {
+	return func(meta models.Meta) ([]*System, models.Meta, error) {
 		metaPtr := &meta
 
-		systems, metaPtr, err := api.ListSystems(c.Get, metaPtr)
+		systems, metaPtr, err := api.ListSystems(get, metaPtr)
 
 		var convertedSystems []*System
 		for _, modelSystem := range systems {
@@ -68,7 +118,6 @@ func ListSystems(c *client.Client) ([]*System, error) {
 			return convertedSystems, defaultMeta, nil
 		}
 	}
-	return client.NewPaginator[*System](fetchFunc).FetchAllPages()
 }
 
 func GetSystem(c *client.Client, symbol string) (*System, error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"os"
+	"time"
 
 	"github.com/jjkirkpatrick/spacetraders-client/client"
 	"github.com/jjkirkpatrick/spacetraders-client/entities"
@@ -20,6 +21,9 @@ func main() {
 	options.Symbol = "CACHE-DEMO"
 	options.Faction = "COSMIC"
 	options.LogLevel = slog.LevelInfo
+	// Systems and waypoints are cached until the next reset by DefaultCachePolicy;
+	// CacheDir also keeps them on disk across restarts
+	options.CacheDir = ".spacetraders-cache"
 	options.TelemetryOptions = client.DefaultTelemetryOptions()
 	options.TelemetryOptions.ServiceName = "spacetraders-caching"
 	options.TelemetryOptions.ServiceVersion = "1.0.0"
@@ -59,12 +63,6 @@ func main() {
 	slog.InfoContext(ctx, "Systems fetched from API", "count", len(systems))
 	fetchSpan.End()
 
-	// Store in cache
-	ctx, cacheSpan := tracer.Start(ctx, "cache_systems")
-	c.CacheClient.Set("systems", systems, 0)
-	slog.InfoContext(ctx, "Systems stored in cache", "count", len(systems))
-	cacheSpan.End()
-
 	// Display first few systems
 	displayCount := 5
 	if len(systems) < displayCount {
@@ -82,23 +80,23 @@ func main() {
 		slog.InfoContext(ctx, "Additional systems not displayed", "remaining", len(systems)-displayCount)
 	}
 
-	// Retrieve from cache
+	// Fetch again: the responses are answered from the cache, and from CacheDir after a restart
 	ctx, retrieveSpan := tracer.Start(ctx, "retrieve_from_cache")
-	retrieveSpan.SetAttributes(attribute.String("cache.key", "systems"))
-	convertedSystems := []*entities.System{}
-	if cachedSystems, found := c.CacheClient.Get("systems"); found {
-		convertedSystems = cachedSystems.([]*entities.System)
-		slog.InfoContext(ctx, "Systems retrieved from cache",
-			"count", len(convertedSystems),
-			"cache_hit", true,
-		)
-	} else {
-		slog.WarnContext(ctx, "Cache miss for systems key", "cache_hit", false)
+	start := time.Now()
+	cachedSystems, err := entities.ListSystems(c)
+	if err != nil {
+		retrieveSpan.RecordError(err)
+		slog.ErrorContext(ctx, "Failed to list systems", "error", err)
+		os.Exit(1)
 	}
+	slog.InfoContext(ctx, "Systems retrieved from cache",
+		"count", len(cachedSystems),
+		"duration", time.Since(start),
+	)
 	retrieveSpan.End()
 
 	slog.InfoContext(ctx, "Caching demonstration complete",
 		"fetched", len(systems),
-		"cached", len(convertedSystems),
+		"cached", len(cachedSystems),
 	)
 }
@@ -1,78 +0,0 @@
-package cache
-
-import (
-	"sync"
-	"time"
-)
-
-// CacheItem represents a single item in the cache
-type CacheItem struct {
-	Value      interface{}
-	Expiration int64
-}
-
-// Cache represents the in-memory cache
-type Cache struct {
-	items map[string]CacheItem
-	mutex sync.RWMutex
-}
-
-// NewCache creates a new Cache instance
-func NewCache() *Cache {
-	return &Cache{
-		items: make(map[string]CacheItem),
-	}
-}
-
-// Set adds an item to the cache, with an optional expiration (in seconds)
-func (c *Cache) Set(key string, value interface{}, expiration int64) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.items[key] = CacheItem{
-		Value:      value,
-		Expiration: time.Now().Unix() + expiration,
-	}
-}
-
-// Get retrieves an item from the cache
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	item, found := c.items[key]
-	if !found {
-		return nil, false
-	}
-
-	if item.Expiration > 0 && time.Now().Unix() > item.Expiration {
-		delete(c.items, key)
-		return nil, false
-	}
-
-	return item.Value, true
-}
-
-// Delete removes an item from the cache
-func (c *Cache) Delete(key string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	delete(c.items, key)
-}
-
-// Clear removes all items from the cache
-func (c *Cache) Clear() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
-
-	c.items = make(map[string]CacheItem)
-}
-
-// Size returns the number of items in the cache
-func (c *Cache) Size() int {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
-
-	return len(c.items)
-}
@@ -1,78 +1,380 @@
 package cache
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	// DefaultExpiration uses the cache's Options.DefaultTTL
+	DefaultExpiration time.Duration = 0
+	// NoExpiration keeps an item until it is deleted or evicted
+	NoExpiration time.Duration = -1
 )
 
-// CacheItem represents a single item in the cache
-type CacheItem struct {
-	Value      interface{}
-	Expiration int64
+// Options configures a Cache
+type Options[K comparable, V any] struct {
+	// MaxEntries bounds the number of items (default: 0, unbounded)
+	MaxEntries int
+	// MaxBytes bounds the total size of the items as reported by SizeOf
+	// (default: 0, unbounded). It is ignored without SizeOf.
+	MaxBytes int64
+	// SizeOf returns the approximate size of an item in bytes
+	SizeOf func(key K, value V) int64
+	// DefaultTTL is the expiration used for DefaultExpiration (default: NoExpiration)
+	DefaultTTL time.Duration
+	// JanitorInterval is how often expired items are removed in the background
+	// (default: 0, expired items are only removed when read or evicted)
+	JanitorInterval time.Duration
+	// OnEvict is called without the cache lock held when an item is evicted to respect
+	// the bounds or removed after expiring
+	OnEvict func(key K, value V)
+}
+
+// Stats are the counters of a Cache since it was created
+type Stats struct {
+	Hits        int64
+	Misses      int64
+	Evictions   int64
+	Expirations int64
+	Entries     int
+	Bytes       int64
+}
+
+// item is a cached value in the LRU list
+type item[K comparable, V any] struct {
+	key     K
+	value   V
+	size    int64
+	expires time.Time // zero for NoExpiration
+}
+
+func (i *item[K, V]) expired(now time.Time) bool {
+	return !i.expires.IsZero() && !now.Before(i.expires)
 }
 
-// Cache represents the in-memory cache
-type Cache struct {
-	items map[string]CacheItem
-	mutex sync.RWMutex
+// Cache is an in-memory cache bounded by entry count and size, evicting the least
+// recently used items first. It is safe for concurrent use.
+type Cache[K comparable, V any] struct {
+	options Options[K, V]
+
+	mu    sync.Mutex
+	items map[K]*list.Element
+	lru   *list.List // Front is the most recently used
+	bytes int64
+	stats Stats
+
+	registration metric.Registration
+	stop         chan struct{}
+	stopOnce     sync.Once
 }
 
-// NewCache creates a new Cache instance
-func NewCache() *Cache {
-	return &Cache{
-		items: make(map[string]CacheItem),
+// New creates a cache, starting the janitor if options.JanitorInterval is set.
+// Call Close to stop the janitor.
+func New[K comparable, V any](options Options[K, V]) *Cache[K, V] {
+	if options.DefaultTTL == DefaultExpiration {
+		options.DefaultTTL = NoExpiration
 	}
+
+	c := &Cache[K, V]{
+		options: options,
+		items:   make(map[K]*list.Element),
+		lru:     list.New(),
+		stop:    make(chan struct{}),
+	}
+
+	if options.JanitorInterval > 0 {
+		go c.janitor(options.JanitorInterval)
+	}
+	return c
 }
 
-// Set adds an item to the cache, with an optional expiration (in seconds)
-func (c *Cache) Set(key string, value interface{}, expiration int64) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// Set stores value under key for ttl, which may be DefaultExpiration or NoExpiration,
+// evicting the least recently used items if the cache is over its bounds
+func (c *Cache[K, V]) Set(key K, value V, ttl time.Duration) {
+	if ttl == DefaultExpiration {
+		ttl = c.options.DefaultTTL
+	}
+
+	entry := &item[K, V]{key: key, value: value}
+	if ttl > 0 {
+		entry.expires = time.Now().Add(ttl)
+	}
+	if c.options.MaxBytes > 0 && c.options.SizeOf != nil {
+		entry.size = c.options.SizeOf(key, value)
+	}
 
-	c.items[key] = CacheItem{
-		Value:      value,
-		Expiration: time.Now().Unix() + expiration,
+	c.mu.Lock()
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
 	}
+	c.items[key] = c.lru.PushFront(entry)
+	c.bytes += entry.size
+	evicted := c.evict()
+	c.mu.Unlock()
+
+	c.notify(evicted)
 }
 
-// Get retrieves an item from the cache
-func (c *Cache) Get(key string) (interface{}, bool) {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// Get returns the value stored under key, and false if it is missing or expired
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	var zero V
+
+	c.mu.Lock()
+	element, ok := c.items[key]
+	if !ok {
+		c.stats.Misses++
+		c.mu.Unlock()
+		return zero, false
+	}
+
+	entry := element.Value.(*item[K, V])
+	if entry.expired(time.Now()) {
+		c.removeElement(element)
+		c.stats.Expirations++
+		c.stats.Misses++
+		c.mu.Unlock()
 
-	item, found := c.items[key]
-	if !found {
-		return nil, false
+		c.notify([]*item[K, V]{entry})
+		return zero, false
 	}
 
-	if item.Expiration > 0 && time.Now().Unix() > item.Expiration {
-		delete(c.items, key)
-		return nil, false
+	c.lru.MoveToFront(element)
+	c.stats.Hits++
+	c.mu.Unlock()
+
+	return entry.value, true
+}
+
+// Delete removes the item stored under key
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
 	}
+}
+
+// DeleteFunc removes every item for which match returns true, returning how many were removed
+func (c *Cache[K, V]) DeleteFunc(match func(key K, value V) bool) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	return item.Value, true
+	removed := 0
+	for _, element := range c.items {
+		if entry := element.Value.(*item[K, V]); match(entry.key, entry.value) {
+			c.removeElement(element)
+			removed++
+		}
+	}
+	return removed
 }
 
-// Delete removes an item from the cache
-func (c *Cache) Delete(key string) {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// Range calls fn for every unexpired item, from the most to the least recently used, until
+// fn returns false. Expires is zero for items that never expire. Items are not marked as
+// used, and fn must not modify the cache.
+func (c *Cache[K, V]) Range(fn func(key K, value V, expires time.Time) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	delete(c.items, key)
+	now := time.Now()
+	for element := c.lru.Front(); element != nil; element = element.Next() {
+		entry := element.Value.(*item[K, V])
+		if entry.expired(now) {
+			continue
+		}
+		if !fn(entry.key, entry.value, entry.expires) {
+			return
+		}
+	}
 }
 
-// Clear removes all items from the cache
-func (c *Cache) Clear() {
-	c.mutex.Lock()
-	defer c.mutex.Unlock()
+// Clear removes every item
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	c.items = make(map[string]CacheItem)
+	c.items = make(map[K]*list.Element)
+	c.lru.Init()
+	c.bytes = 0
 }
 
-// Size returns the number of items in the cache
-func (c *Cache) Size() int {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// Size returns the number of items, including expired items not yet removed
+func (c *Cache[K, V]) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
 	return len(c.items)
 }
+
+// Stats returns the cache's counters
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := c.stats
+	stats.Entries = len(c.items)
+	stats.Bytes = c.bytes
+	return stats
+}
+
+// DeleteExpired removes every expired item
+func (c *Cache[K, V]) DeleteExpired() {
+	now := time.Now()
+
+	c.mu.Lock()
+	var expired []*item[K, V]
+	for _, element := range c.items {
+		if entry := element.Value.(*item[K, V]); entry.expired(now) {
+			c.removeElement(element)
+			expired = append(expired, entry)
+		}
+	}
+	c.stats.Expirations += int64(len(expired))
+	c.mu.Unlock()
+
+	c.notify(expired)
+}
+
+// RegisterMetrics reports the cache's hits, misses, evictions, expirations, entries and
+// bytes through meter, labelled with attrs. The callback is unregistered by Close.
+func (c *Cache[K, V]) RegisterMetrics(meter metric.Meter, attrs ...attribute.KeyValue) error {
+	hits, err := meter.Int64ObservableCounter("cache_hits_total",
+		metric.WithDescription("Total number of cache lookups that found an item"),
+		metric.WithUnit("{lookups}"))
+	if err != nil {
+		return err
+	}
+	misses, err := meter.Int64ObservableCounter("cache_misses_total",
+		metric.WithDescription("Total number of cache lookups that found no item"),
+		metric.WithUnit("{lookups}"))
+	if err != nil {
+		return err
+	}
+	evictions, err := meter.Int64ObservableCounter("cache_evictions_total",
+		metric.WithDescription("Total number of items evicted to respect the cache bounds"),
+		metric.WithUnit("{items}"))
+	if err != nil {
+		return err
+	}
+	expirations, err := meter.Int64ObservableCounter("cache_expirations_total",
+		metric.WithDescription("Total number of expired items removed from the cache"),
+		metric.WithUnit("{items}"))
+	if err != nil {
+		return err
+	}
+	entries, err := meter.Int64ObservableGauge("cache_entries",
+		metric.WithDescription("Number of items in the cache"),
+		metric.WithUnit("{items}"))
+	if err != nil {
+		return err
+	}
+	bytes, err := meter.Int64ObservableGauge("cache_bytes",
+		metric.WithDescription("Approximate size of the items in the cache"),
+		metric.WithUnit("By"))
+	if err != nil {
+		return err
+	}
+
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		stats := c.Stats()
+		options := metric.WithAttributes(attrs...)
+		o.ObserveInt64(hits, stats.Hits, options)
+		o.ObserveInt64(misses, stats.Misses, options)
+		o.ObserveInt64(evictions, stats.Evictions, options)
+		o.ObserveInt64(expirations, stats.Expirations, options)
+		o.ObserveInt64(entries, int64(stats.Entries), options)
+		o.ObserveInt64(bytes, stats.Bytes, options)
+		return nil
+	}, hits, misses, evictions, expirations, entries, bytes)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.registration = registration
+	c.mu.Unlock()
+	return nil
+}
+
+// Close stops the janitor and unregisters the metrics callback. The cache stays usable.
+func (c *Cache[K, V]) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+
+	c.mu.Lock()
+	registration := c.registration
+	c.registration = nil
+	c.mu.Unlock()
+
+	if registration != nil {
+		return registration.Unregister()
+	}
+	return nil
+}
+
+// janitor removes expired items every interval until the cache is closed
+func (c *Cache[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.DeleteExpired()
+		}
+	}
+}
+
+// evict removes the least recently used items until the cache is within its bounds,
+// returning them. Expired items elsewhere in the list are left to the janitor and Get,
+// so evicting stays constant time per item. c.mu must be held.
+func (c *Cache[K, V]) evict() []*item[K, V] {
+	var evicted []*item[K, V]
+	now := time.Now()
+	for c.overBounds() && c.lru.Len() > 0 {
+		element := c.lru.Back()
+		entry := element.Value.(*item[K, V])
+		c.removeElement(element)
+		if entry.expired(now) {
+			c.stats.Expirations++
+		} else {
+			c.stats.Evictions++
+		}
+		evicted = append(evicted, entry)
+	}
+	return evicted
+}
+
+// overBounds reports whether the cache holds more than its bounds allow. c.mu must be held.
+func (c *Cache[K, V]) overBounds() bool {
+	if c.options.MaxEntries > 0 && len(c.items) > c.options.MaxEntries {
+		return true
+	}
+	return c.options.MaxBytes > 0 && c.options.SizeOf != nil && c.bytes > c.options.MaxBytes
+}
+
+// removeElement removes an item from the map and LRU list. c.mu must be held.
+func (c *Cache[K, V]) removeElement(element *list.Element) {
+	entry := element.Value.(*item[K, V])
+	c.lru.Remove(element)
+	delete(c.items, entry.key)
+	c.bytes -= entry.size
+}
+
+// notify calls OnEvict for removed items
+func (c *Cache[K, V]) notify(removed []*item[K, V]) {
+	if c.options.OnEvict == nil {
+		return
+	}
+	for _, entry := range removed {
+		c.options.OnEvict(entry.key, entry.value)
+	}
+}
@@ -1,15 +1,25 @@
 package cache
 
 import (
+	"context"
+	"sync"
 	"testing"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 )
 
+func newCache() *Cache[string, string] {
+	return New(Options[string, string]{})
+}
+
 func TestCache_SetAndGet(t *testing.T) {
-	cache := NewCache()
+	cache := newCache()
 	key := "testKey"
 	value := "testValue"
-	expiration := int64(5) // 5 seconds
+	expiration := 50 * time.Millisecond
 
 	cache.Set(key, value, expiration)
 
@@ -23,19 +33,34 @@ func TestCache_SetAndGet(t *testing.T) {
 	}
 
 	// Test expiration
-	time.Sleep(6 * time.Second)
+	time.Sleep(60 * time.Millisecond)
 	_, foundAfterExpiration := cache.Get(key)
 	if foundAfterExpiration {
 		t.Errorf("Expected not to find value for key %s after expiration", key)
 	}
 }
 
+func TestCache_NoExpiration(t *testing.T) {
+	cache := newCache()
+	cache.Set("forever", "value", NoExpiration)
+	cache.Set("default", "value", DefaultExpiration)
+
+	time.Sleep(1100 * time.Millisecond)
+
+	if _, found := cache.Get("forever"); !found {
+		t.Errorf("Expected NoExpiration items to be kept")
+	}
+	if _, found := cache.Get("default"); !found {
+		t.Errorf("Expected DefaultExpiration to mean NoExpiration without a DefaultTTL")
+	}
+}
+
 func TestCache_Delete(t *testing.T) {
-	cache := NewCache()
+	cache := newCache()
 	key := "testKey"
 	value := "testValue"
 
-	cache.Set(key, value, 0)
+	cache.Set(key, value, NoExpiration)
 	cache.Delete(key)
 
 	_, found := cache.Get(key)
@@ -45,9 +70,9 @@ func TestCache_Delete(t *testing.T) {
 }
 
 func TestCache_Clear(t *testing.T) {
-	cache := NewCache()
-	cache.Set("key1", "value1", 0)
-	cache.Set("key2", "value2", 0)
+	cache := newCache()
+	cache.Set("key1", "value1", NoExpiration)
+	cache.Set("key2", "value2", NoExpiration)
 
 	cache.Clear()
 
@@ -57,12 +82,173 @@ func TestCache_Clear(t *testing.T) {
 }
 
 func TestCache_Size(t *testing.T) {
-	cache := NewCache()
-	cache.Set("key1", "value1", 0)
-	cache.Set("key2", "value2", 0)
+	cache := newCache()
+	cache.Set("key1", "value1", NoExpiration)
+	cache.Set("key2", "value2", NoExpiration)
 
 	size := cache.Size()
 	if size != 2 {
 		t.Errorf("Expected cache size to be 2, got %d", size)
 	}
 }
+
+func TestCache_LRUEviction(t *testing.T) {
+	var evicted []string
+	cache := New(Options[string, int]{
+		MaxEntries: 2,
+		OnEvict:    func(key string, _ int) { evicted = append(evicted, key) },
+	})
+
+	cache.Set("a", 1, NoExpiration)
+	cache.Set("b", 2, NoExpiration)
+	cache.Get("a") // b is now the least recently used
+	cache.Set("c", 3, NoExpiration)
+
+	if _, found := cache.Get("b"); found {
+		t.Errorf("Expected the least recently used item to be evicted")
+	}
+	if _, found := cache.Get("a"); !found {
+		t.Errorf("Expected recently used items to be kept")
+	}
+	if len(evicted) != 1 || evicted[0] != "b" {
+		t.Errorf("Expected OnEvict to be called for b, got %v", evicted)
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 || stats.Entries != 2 {
+		t.Errorf("Expected 1 eviction and 2 entries, got %+v", stats)
+	}
+}
+
+func TestCache_MaxBytes(t *testing.T) {
+	cache := New(Options[string, string]{
+		MaxBytes: 10,
+		SizeOf:   func(_ string, value string) int64 { return int64(len(value)) },
+	})
+
+	cache.Set("a", "aaaa", NoExpiration)
+	cache.Set("b", "bbbb", NoExpiration)
+	cache.Set("c", "cccc", NoExpiration)
+
+	if _, found := cache.Get("a"); found {
+		t.Errorf("Expected the oldest item to be evicted once over MaxBytes")
+	}
+	if stats := cache.Stats(); stats.Bytes != 8 {
+		t.Errorf("Expected 8 bytes cached, got %d", stats.Bytes)
+	}
+
+	cache.Set("huge", "far too large to cache", NoExpiration)
+	if _, found := cache.Get("huge"); found {
+		t.Errorf("Expected items larger than MaxBytes not to be kept")
+	}
+}
+
+func TestCache_Janitor(t *testing.T) {
+	var mu sync.Mutex
+	var expired []string
+	cache := New(Options[string, string]{
+		JanitorInterval: 10 * time.Millisecond,
+		OnEvict: func(key string, _ string) {
+			mu.Lock()
+			expired = append(expired, key)
+			mu.Unlock()
+		},
+	})
+	defer cache.Close()
+
+	cache.Set("short", "value", 20*time.Millisecond)
+	cache.Set("long", "value", time.Hour)
+
+	time.Sleep(100 * time.Millisecond)
+
+	if cache.Size() != 1 {
+		t.Errorf("Expected the janitor to remove expired items, got size %d", cache.Size())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(expired) != 1 || expired[0] != "short" {
+		t.Errorf("Expected OnEvict to be called for short, got %v", expired)
+	}
+}
+
+func TestCache_Metrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	cache := newCache()
+	if err := cache.RegisterMetrics(provider.Meter("test"), attribute.String("cache", "test")); err != nil {
+		t.Fatalf("Failed to register metrics: %v", err)
+	}
+
+	cache.Set("key", "value", NoExpiration)
+	cache.Get("key")
+	cache.Get("key")
+	cache.Get("missing")
+
+	var data metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &data); err != nil {
+		t.Fatalf("Failed to collect metrics: %v", err)
+	}
+
+	values := make(map[string]int64)
+	for _, scope := range data.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			switch d := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				values[m.Name] = d.DataPoints[0].Value
+			case metricdata.Gauge[int64]:
+				values[m.Name] = d.DataPoints[0].Value
+			}
+		}
+	}
+
+	if values["cache_hits_total"] != 2 || values["cache_misses_total"] != 1 || values["cache_entries"] != 1 {
+		t.Errorf("Unexpected cache metrics: %v", values)
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Errorf("Failed to close cache: %v", err)
+	}
+}
+
+func TestCache_DeleteFunc(t *testing.T) {
+	cache := newCache()
+	cache.Set("GET /systems/X1/waypoints/X1-A1/market", "market", NoExpiration)
+	cache.Set("GET /systems/X1/waypoints/X1-A2/market", "market", NoExpiration)
+	cache.Set("GET /systems/X1", "system", NoExpiration)
+
+	removed := cache.DeleteFunc(func(key string, value string) bool {
+		return value == "market"
+	})
+
+	if removed != 2 {
+		t.Errorf("Expected 2 items to be removed, got %d", removed)
+	}
+	if cache.Size() != 1 {
+		t.Errorf("Expected cache size to be 1, got %d", cache.Size())
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsedFirst(t *testing.T) {
+	cache := New(Options[string, int]{MaxEntries: 2})
+
+	cache.Set("old", 1, NoExpiration)
+	cache.Set("expiring", 2, 10*time.Millisecond)
+	cache.Get("old") // expiring is now the least recently used
+	time.Sleep(20 * time.Millisecond)
+
+	// Only the back of the list is evicted, counting expired items as expirations
+	cache.Set("new", 3, NoExpiration)
+	if _, found := cache.Get("old"); !found {
+		t.Errorf("Expected recently used items to be kept")
+	}
+	if stats := cache.Stats(); stats.Expirations != 1 || stats.Evictions != 0 {
+		t.Errorf("Expected 1 expiration and no evictions, got %+v", stats)
+	}
+
+	cache.Set("newer", 4, NoExpiration)
+	if _, found := cache.Get("new"); found {
+		t.Errorf("Expected the least recently used item to be evicted")
+	}
+	if stats := cache.Stats(); stats.Evictions != 1 {
+		t.Errorf("Expected 1 eviction, got %+v", stats)
+	}
+}
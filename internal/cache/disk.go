@@ -0,0 +1,212 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// DiskStore persists values as JSON files in a directory, grouped by namespace. Only one
+// namespace is kept: setting a new one deletes the files of every other namespace, so data
+// stored for a previous game reset is dropped. Files outside the namespace directories are
+// never touched. It is safe for concurrent use.
+type DiskStore struct {
+	mu        sync.Mutex
+	dir       string
+	namespace string
+}
+
+// NewDiskStore creates a store keeping its files in dir, which is created when first written.
+// Nothing is loaded or saved until a namespace is set.
+func NewDiskStore(dir string) *DiskStore {
+	return &DiskStore{dir: dir}
+}
+
+var unsafeDiskChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// namespacePrefix marks the directories holding namespaces
+const namespacePrefix = "ns-"
+
+// diskName maps a key or namespace to a file name
+func diskName(name string) string {
+	return unsafeDiskChars.ReplaceAllString(name, "_")
+}
+
+// Namespace returns the current namespace, empty if none is set
+func (s *DiskStore) Namespace() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.namespace
+}
+
+// SetNamespace switches the store to namespace and deletes the files of every other
+// namespace. An empty namespace disables the store without deleting anything.
+func (s *DiskStore) SetNamespace(namespace string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.namespace = namespace
+	if namespace == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	current := namespacePrefix + diskName(namespace)
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), namespacePrefix) && entry.Name() != current {
+			if err := os.RemoveAll(filepath.Join(s.dir, entry.Name())); err != nil {
+				return fmt.Errorf("failed to delete stale cache namespace %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// path returns the file holding key in the current namespace. s.mu must be held.
+func (s *DiskStore) path(key string) string {
+	return filepath.Join(s.dir, namespacePrefix+diskName(s.namespace), diskName(key)+".json")
+}
+
+// Load decodes the value stored under key into value, reporting whether it was found.
+// Nothing is found while no namespace is set.
+func (s *DiskStore) Load(key string, value any) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.namespace == "" {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(s.path(key))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read cached %s: %w", key, err)
+	}
+
+	if err := json.Unmarshal(data, value); err != nil {
+		return false, fmt.Errorf("failed to decode cached %s: %w", key, err)
+	}
+	return true, nil
+}
+
+// Save stores value under key in the current namespace. The file is replaced atomically,
+// so a crash never leaves a partially written value. Nothing is saved while no namespace is set.
+func (s *DiskStore) Save(key string, value any) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.namespace == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", key, err)
+	}
+
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), diskName(key)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write cached %s: %w", key, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write cached %s: %w", key, err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cached %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes the value stored under key in the current namespace
+func (s *DiskStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.namespace == "" {
+		return nil
+	}
+
+	if err := os.Remove(s.path(key)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete cached %s: %w", key, err)
+	}
+	return nil
+}
+
+// DeletePrefix removes the values stored under keys starting with prefix in the current
+// namespace. Keys are matched by file name, so keys that only differ in characters that
+// are not allowed in file names may also be removed.
+func (s *DiskStore) DeletePrefix(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.namespace == "" {
+		return nil
+	}
+
+	dir := filepath.Dir(s.path(prefix))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	name := diskName(prefix)
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(entry.Name(), name) && strings.HasSuffix(entry.Name(), ".json") {
+			if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to delete cached %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Clear deletes every namespace, including the current one
+func (s *DiskStore) Clear() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() && strings.HasPrefix(entry.Name(), namespacePrefix) {
+			if err := os.RemoveAll(filepath.Join(s.dir, entry.Name())); err != nil {
+				return fmt.Errorf("failed to clear cache namespace %s: %w", entry.Name(), err)
+			}
+		}
+	}
+	return nil
+}
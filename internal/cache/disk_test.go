@@ -0,0 +1,121 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type diskValue struct {
+	Symbol string
+	Count  int
+}
+
+func TestDiskStore_SaveAndLoad(t *testing.T) {
+	store := NewDiskStore(t.TempDir())
+
+	var value diskValue
+	if err := store.Save("system_X1", diskValue{Symbol: "X1", Count: 3}); err != nil {
+		t.Fatalf("Failed to save without a namespace: %v", err)
+	}
+	if found, _ := store.Load("system_X1", &value); found {
+		t.Errorf("Expected nothing to be stored without a namespace")
+	}
+
+	if err := store.SetNamespace("2026-10-18"); err != nil {
+		t.Fatalf("Failed to set namespace: %v", err)
+	}
+	if err := store.Save("system_X1", diskValue{Symbol: "X1", Count: 3}); err != nil {
+		t.Fatalf("Failed to save: %v", err)
+	}
+
+	// Cached data is private to the user running the client
+	path := store.path("system_X1")
+	for name, want := range map[string]os.FileMode{filepath.Dir(path): 0700, path: 0600} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Failed to stat %s: %v", name, err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("Expected %s to have mode %v, got %v", name, want, info.Mode().Perm())
+		}
+	}
+
+	// A new store reads what an earlier process saved
+	restarted := NewDiskStore(store.dir)
+	if err := restarted.SetNamespace("2026-10-18"); err != nil {
+		t.Fatalf("Failed to set namespace: %v", err)
+	}
+	found, err := restarted.Load("system_X1", &value)
+	if err != nil || !found {
+		t.Fatalf("Expected to load the saved value, found %v, error %v", found, err)
+	}
+	if value != (diskValue{Symbol: "X1", Count: 3}) {
+		t.Errorf("Expected the saved value, got %+v", value)
+	}
+
+	if err := restarted.Delete("system_X1"); err != nil {
+		t.Fatalf("Failed to delete: %v", err)
+	}
+	if found, _ := restarted.Load("system_X1", &value); found {
+		t.Errorf("Expected the value to be deleted")
+	}
+}
+
+func TestDiskStore_NewNamespaceDropsOthers(t *testing.T) {
+	dir := t.TempDir()
+	unrelated := filepath.Join(dir, "unrelated")
+	if err := os.Mkdir(unrelated, 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	store := NewDiskStore(dir)
+	store.SetNamespace("2026-10-11")
+	store.Save("system_X1", diskValue{Symbol: "X1"})
+
+	if err := store.SetNamespace("2026-10-18"); err != nil {
+		t.Fatalf("Failed to set namespace: %v", err)
+	}
+
+	var value diskValue
+	if found, _ := store.Load("system_X1", &value); found {
+		t.Errorf("Expected data from the previous namespace to be dropped")
+	}
+	if _, err := os.Stat(filepath.Join(dir, namespacePrefix+"2026-10-11")); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous namespace to be deleted from disk")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("Expected directories that are not namespaces to be kept: %v", err)
+	}
+
+	store.Save("system_X2", diskValue{Symbol: "X2"})
+	if err := store.Clear(); err != nil {
+		t.Fatalf("Failed to clear: %v", err)
+	}
+	if found, _ := store.Load("system_X2", &value); found {
+		t.Errorf("Expected Clear to delete the current namespace")
+	}
+	if _, err := os.Stat(unrelated); err != nil {
+		t.Errorf("Expected Clear to keep directories that are not namespaces: %v", err)
+	}
+}
+
+func TestDiskStore_DeletePrefix(t *testing.T) {
+	store := NewDiskStore(t.TempDir())
+	store.SetNamespace("2026-10-18")
+	store.Save("GET /systems/X1/waypoints?page=1", diskValue{Count: 1})
+	store.Save("GET /systems/X1/waypoints?page=2", diskValue{Count: 2})
+	store.Save("GET /systems/X2/waypoints?page=1", diskValue{Count: 3})
+
+	if err := store.DeletePrefix("GET /systems/X1/waypoints?"); err != nil {
+		t.Fatalf("Failed to delete prefix: %v", err)
+	}
+
+	var value diskValue
+	if found, _ := store.Load("GET /systems/X1/waypoints?page=2", &value); found {
+		t.Errorf("Expected keys with the prefix to be deleted")
+	}
+	if found, _ := store.Load("GET /systems/X2/waypoints?page=1", &value); !found {
+		t.Errorf("Expected keys without the prefix to be kept")
+	}
+}
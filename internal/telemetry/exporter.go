@@ -0,0 +1,24 @@
+package telemetry
+
+import publictelemetry "github.com/jjkirkpatrick/spacetraders-client/telemetry"
+
+// Exporter re-exports the public Exporter type for internal use.
+type Exporter = publictelemetry.Exporter
+
+// InMemoryExporter re-exports the public InMemoryExporter type for internal use.
+type InMemoryExporter = publictelemetry.InMemoryExporter
+
+// Exporter values re-exported from the public telemetry package.
+const (
+	ExporterOTLP       = publictelemetry.ExporterOTLP
+	ExporterOTLPHTTP   = publictelemetry.ExporterOTLPHTTP
+	ExporterStdout     = publictelemetry.ExporterStdout
+	ExporterMemory     = publictelemetry.ExporterMemory
+	ExporterPrometheus = publictelemetry.ExporterPrometheus
+)
+
+// NewInMemoryExporter creates an empty in-memory exporter.
+// This is a re-export of the public telemetry package function.
+func NewInMemoryExporter() *InMemoryExporter {
+	return publictelemetry.NewInMemoryExporter()
+}
@@ -3,13 +3,26 @@ package telemetry
 import (
 	"context"
 	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutlog"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
 	"go.opentelemetry.io/otel/log/global"
 	"go.opentelemetry.io/otel/propagation"
 	sdklog "go.opentelemetry.io/otel/sdk/log"
@@ -32,10 +45,30 @@ type Config struct {
 	// Environment describes the deployment environment (e.g., "development", "production")
 	Environment string
 
-	// OTLPEndpoint is the gRPC endpoint for your OpenTelemetry collector (required)
+	// OTLPEndpoint is the gRPC endpoint for your OpenTelemetry collector
+	// (required when any enabled signal uses the OTLP exporter)
 	// Example: "localhost:4317" or "otel-collector.example.com:4317"
 	OTLPEndpoint string
 
+	// OTLPHTTPEndpoint is the endpoint used by signals with the OTLP HTTP exporter.
+	// Either host:port (sent over plain HTTP) or a full URL such as "https://collector:4318".
+	// Defaults to OTLPEndpoint.
+	OTLPHTTPEndpoint string
+
+	// MetricExporter selects the metrics exporter (default: ExporterOTLP)
+	MetricExporter Exporter
+
+	// TraceExporter selects the trace exporter (default: ExporterOTLP)
+	TraceExporter Exporter
+
+	// LogExporter selects the log exporter (default: ExporterOTLP)
+	LogExporter Exporter
+
+	// PrometheusAddr is the address to serve /metrics on when MetricExporter is
+	// ExporterPrometheus, e.g. ":9464". If empty, no server is started and the
+	// handler must be mounted by the caller.
+	PrometheusAddr string
+
 	// MetricInterval controls how frequently metrics are exported
 	MetricInterval time.Duration
 
@@ -81,8 +114,18 @@ type Providers struct {
 	LoggerProvider *sdklog.LoggerProvider
 	Resource       *resource.Resource
 
+	// MetricsHandler serves metrics in the Prometheus text format.
+	// It is only set when the Prometheus exporter is used.
+	MetricsHandler http.Handler
+
+	// InMemory holds the telemetry of every signal using the in-memory exporter.
+	// It is only set when at least one signal uses ExporterMemory.
+	InMemory *InMemoryExporter
+
 	// Internal: gRPC connection for cleanup
 	conn *grpc.ClientConn
+	// Internal: Prometheus HTTP server, if one was started
+	metricsServer *http.Server
 }
 
 // InitTelemetry initializes OpenTelemetry with the provided configuration.
@@ -91,9 +134,43 @@ func InitTelemetry(ctx context.Context, cfg Config) (*Providers, error) {
 	if cfg.ServiceName == "" {
 		return nil, fmt.Errorf("service name is required")
 	}
-	if cfg.OTLPEndpoint == "" {
+
+	cfg.MetricExporter = defaultExporter(cfg.MetricExporter)
+	cfg.TraceExporter = defaultExporter(cfg.TraceExporter)
+	cfg.LogExporter = defaultExporter(cfg.LogExporter)
+	if cfg.OTLPHTTPEndpoint == "" {
+		cfg.OTLPHTTPEndpoint = cfg.OTLPEndpoint
+	}
+
+	if err := validateExporter("metric", cfg.MetricExporter, true); err != nil {
+		return nil, err
+	}
+	if err := validateExporter("trace", cfg.TraceExporter, false); err != nil {
+		return nil, err
+	}
+	if err := validateExporter("log", cfg.LogExporter, false); err != nil {
+		return nil, err
+	}
+
+	// Collect the exporters of the enabled signals to work out which connections are needed
+	var enabled []Exporter
+	if cfg.EnableMetrics {
+		enabled = append(enabled, cfg.MetricExporter)
+	}
+	if cfg.EnableTracing {
+		enabled = append(enabled, cfg.TraceExporter)
+	}
+	if cfg.EnableLogging {
+		enabled = append(enabled, cfg.LogExporter)
+	}
+
+	needsGRPC := slices.Contains(enabled, ExporterOTLP)
+	if needsGRPC && cfg.OTLPEndpoint == "" {
 		return nil, fmt.Errorf("OTLP endpoint is required")
 	}
+	if slices.Contains(enabled, ExporterOTLPHTTP) && cfg.OTLPHTTPEndpoint == "" {
+		return nil, fmt.Errorf("OTLP HTTP endpoint is required")
+	}
 
 	// Create resource with service information
 	attrs := append([]attribute.KeyValue{
@@ -112,54 +189,58 @@ func InitTelemetry(ctx context.Context, cfg Config) (*Providers, error) {
 		return nil, fmt.Errorf("failed to create resource: %w", err)
 	}
 
-	// Initialize gRPC connection with timeout
-	dialOpts := cfg.GRPCDialOptions
-	if len(dialOpts) == 0 {
-		dialOpts = DefaultConfig().GRPCDialOptions
+	providers := &Providers{
+		Resource: res,
 	}
 
-	// Add a timeout context for dialing
-	dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
-	defer cancel()
-
-	conn, err := grpc.DialContext(dialCtx, cfg.OTLPEndpoint, dialOpts...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create gRPC connection to %s: %w", cfg.OTLPEndpoint, err)
+	if slices.Contains(enabled, ExporterMemory) {
+		providers.InMemory = NewInMemoryExporter()
 	}
 
-	providers := &Providers{
-		Resource: res,
-		conn:     conn,
+	if needsGRPC {
+		// Initialize gRPC connection with timeout
+		dialOpts := cfg.GRPCDialOptions
+		if len(dialOpts) == 0 {
+			dialOpts = DefaultConfig().GRPCDialOptions
+		}
+
+		// Add a timeout context for dialing
+		dialCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		conn, err := grpc.DialContext(dialCtx, cfg.OTLPEndpoint, dialOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create gRPC connection to %s: %w", cfg.OTLPEndpoint, err)
+		}
+		providers.conn = conn
 	}
 
 	// Initialize metrics
 	if cfg.EnableMetrics {
-		metricExp, err := otlpmetricgrpc.New(ctx,
-			otlpmetricgrpc.WithGRPCConn(conn),
-		)
+		reader, err := providers.newMetricReader(ctx, cfg)
 		if err != nil {
-			conn.Close()
+			providers.shutdownPartial(ctx)
 			return nil, fmt.Errorf("failed to create metric exporter: %w", err)
 		}
 
 		mp := sdkmetric.NewMeterProvider(
 			sdkmetric.WithResource(res),
-			sdkmetric.WithReader(
-				sdkmetric.NewPeriodicReader(
-					metricExp,
-					sdkmetric.WithInterval(cfg.MetricInterval),
-				),
-			),
+			sdkmetric.WithReader(reader),
 		)
 		providers.MeterProvider = mp
 		otel.SetMeterProvider(mp)
+
+		if providers.MetricsHandler != nil && cfg.PrometheusAddr != "" {
+			if err := providers.serveMetrics(cfg.PrometheusAddr); err != nil {
+				providers.shutdownPartial(ctx)
+				return nil, err
+			}
+		}
 	}
 
 	// Initialize tracing
 	if cfg.EnableTracing {
-		traceExp, err := otlptracegrpc.New(ctx,
-			otlptracegrpc.WithGRPCConn(conn),
-		)
+		traceExp, err := providers.newSpanExporter(ctx, cfg)
 		if err != nil {
 			providers.shutdownPartial(ctx)
 			return nil, fmt.Errorf("failed to create trace exporter: %w", err)
@@ -175,9 +256,15 @@ func InitTelemetry(ctx context.Context, cfg Config) (*Providers, error) {
 			sampler = sdktrace.TraceIDRatioBased(cfg.TraceSampleRate)
 		}
 
+		// In-memory spans are exported synchronously so tests can see them as soon as they end
+		spanProcessor := sdktrace.WithBatcher(traceExp)
+		if cfg.TraceExporter == ExporterMemory {
+			spanProcessor = sdktrace.WithSyncer(traceExp)
+		}
+
 		tp := sdktrace.NewTracerProvider(
 			sdktrace.WithResource(res),
-			sdktrace.WithBatcher(traceExp),
+			spanProcessor,
 			sdktrace.WithSampler(sampler),
 		)
 		providers.TracerProvider = tp
@@ -192,17 +279,20 @@ func InitTelemetry(ctx context.Context, cfg Config) (*Providers, error) {
 
 	// Initialize logging
 	if cfg.EnableLogging {
-		logExp, err := otlploggrpc.New(ctx,
-			otlploggrpc.WithGRPCConn(conn),
-		)
+		logExp, err := providers.newLogExporter(ctx, cfg)
 		if err != nil {
 			providers.shutdownPartial(ctx)
 			return nil, fmt.Errorf("failed to create log exporter: %w", err)
 		}
 
+		var processor sdklog.Processor = sdklog.NewBatchProcessor(logExp)
+		if cfg.LogExporter == ExporterMemory {
+			processor = sdklog.NewSimpleProcessor(logExp)
+		}
+
 		lp := sdklog.NewLoggerProvider(
 			sdklog.WithResource(res),
-			sdklog.WithProcessor(sdklog.NewBatchProcessor(logExp)),
+			sdklog.WithProcessor(processor),
 		)
 		providers.LoggerProvider = lp
 		global.SetLoggerProvider(lp)
@@ -211,6 +301,121 @@ func InitTelemetry(ctx context.Context, cfg Config) (*Providers, error) {
 	return providers, nil
 }
 
+// defaultExporter returns ExporterOTLP when no exporter was selected
+func defaultExporter(exporter Exporter) Exporter {
+	if exporter == "" {
+		return ExporterOTLP
+	}
+	return exporter
+}
+
+// validateExporter checks an exporter is supported for a signal
+func validateExporter(signal string, exporter Exporter, allowPrometheus bool) error {
+	switch exporter {
+	case ExporterOTLP, ExporterOTLPHTTP, ExporterStdout, ExporterMemory:
+		return nil
+	case ExporterPrometheus:
+		if allowPrometheus {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported %s exporter %q", signal, exporter)
+}
+
+// isEndpointURL reports whether an OTLP HTTP endpoint includes a scheme
+func isEndpointURL(endpoint string) bool {
+	return strings.Contains(endpoint, "://")
+}
+
+// newMetricReader creates the metric reader for the configured exporter
+func (p *Providers) newMetricReader(ctx context.Context, cfg Config) (sdkmetric.Reader, error) {
+	var exporter sdkmetric.Exporter
+	var err error
+
+	switch cfg.MetricExporter {
+	case ExporterPrometheus:
+		registry := prometheus.NewRegistry()
+		promExp, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+		if err != nil {
+			return nil, err
+		}
+		p.MetricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+		return promExp, nil
+
+	case ExporterMemory:
+		return p.InMemory.MetricReader(), nil
+
+	case ExporterOTLPHTTP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.OTLPHTTPEndpoint), otlpmetrichttp.WithInsecure()}
+		if isEndpointURL(cfg.OTLPHTTPEndpoint) {
+			opts = []otlpmetrichttp.Option{otlpmetrichttp.WithEndpointURL(cfg.OTLPHTTPEndpoint)}
+		}
+		exporter, err = otlpmetrichttp.New(ctx, opts...)
+
+	case ExporterStdout:
+		exporter, err = stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+
+	default:
+		exporter, err = otlpmetricgrpc.New(ctx,
+			otlpmetricgrpc.WithGRPCConn(p.conn),
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sdkmetric.NewPeriodicReader(
+		exporter,
+		sdkmetric.WithInterval(cfg.MetricInterval),
+	), nil
+}
+
+// newSpanExporter creates the span exporter for the configured exporter
+func (p *Providers) newSpanExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, error) {
+	switch cfg.TraceExporter {
+	case ExporterMemory:
+		return p.InMemory.SpanExporter(), nil
+
+	case ExporterOTLPHTTP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPHTTPEndpoint), otlptracehttp.WithInsecure()}
+		if isEndpointURL(cfg.OTLPHTTPEndpoint) {
+			opts = []otlptracehttp.Option{otlptracehttp.WithEndpointURL(cfg.OTLPHTTPEndpoint)}
+		}
+		return otlptracehttp.New(ctx, opts...)
+
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithPrettyPrint())
+
+	default:
+		return otlptracegrpc.New(ctx,
+			otlptracegrpc.WithGRPCConn(p.conn),
+		)
+	}
+}
+
+// newLogExporter creates the log exporter for the configured exporter
+func (p *Providers) newLogExporter(ctx context.Context, cfg Config) (sdklog.Exporter, error) {
+	switch cfg.LogExporter {
+	case ExporterMemory:
+		return p.InMemory.LogExporter(), nil
+
+	case ExporterOTLPHTTP:
+		opts := []otlploghttp.Option{otlploghttp.WithEndpoint(cfg.OTLPHTTPEndpoint), otlploghttp.WithInsecure()}
+		if isEndpointURL(cfg.OTLPHTTPEndpoint) {
+			opts = []otlploghttp.Option{otlploghttp.WithEndpointURL(cfg.OTLPHTTPEndpoint)}
+		}
+		return otlploghttp.New(ctx, opts...)
+
+	case ExporterStdout:
+		return stdoutlog.New(stdoutlog.WithPrettyPrint())
+
+	default:
+		return otlploggrpc.New(ctx,
+			otlploggrpc.WithGRPCConn(p.conn),
+		)
+	}
+}
+
 // shutdownPartial shuts down any initialized providers (used during init errors)
 func (p *Providers) shutdownPartial(ctx context.Context) {
 	if p.MeterProvider != nil {
@@ -222,11 +427,33 @@ func (p *Providers) shutdownPartial(ctx context.Context) {
 	if p.LoggerProvider != nil {
 		p.LoggerProvider.Shutdown(ctx)
 	}
+	if p.metricsServer != nil {
+		p.metricsServer.Close()
+	}
 	if p.conn != nil {
 		p.conn.Close()
 	}
 }
 
+// serveMetrics starts an HTTP server exposing the metrics handler on /metrics
+func (p *Providers) serveMetrics(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen for metrics on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", p.MetricsHandler)
+
+	p.metricsServer = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go p.metricsServer.Serve(listener)
+
+	return nil
+}
+
 // Shutdown gracefully shuts down all OpenTelemetry providers.
 // Call this when your application terminates to ensure all telemetry is flushed.
 func (p *Providers) Shutdown(ctx context.Context) error {
@@ -250,6 +477,12 @@ func (p *Providers) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	if p.metricsServer != nil {
+		if err := p.metricsServer.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("metrics server shutdown: %w", err))
+		}
+	}
+
 	if p.conn != nil {
 		if err := p.conn.Close(); err != nil {
 			errs = append(errs, fmt.Errorf("gRPC connection close: %w", err))
@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	otellog "go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInitTelemetry_Prometheus(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServiceName = "test"
+	cfg.MetricExporter = ExporterPrometheus
+	cfg.EnableTracing = false
+	cfg.EnableLogging = false
+
+	// No collector is needed when only Prometheus metrics are enabled
+	providers, err := InitTelemetry(context.Background(), cfg)
+	require.NoError(t, err)
+	defer providers.Shutdown(context.Background())
+
+	require.NotNil(t, providers.MetricsHandler)
+
+	counter, err := providers.MeterProvider.Meter("test").Int64Counter("api_requests_total")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 3)
+
+	recorder := httptest.NewRecorder()
+	providers.MetricsHandler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, 200, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "api_requests_total")
+}
+
+func TestInitTelemetry_RequiresEndpointForOTLP(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServiceName = "test"
+
+	_, err := InitTelemetry(context.Background(), cfg)
+	assert.Error(t, err)
+}
+
+func TestInitTelemetry_InMemory(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServiceName = "test"
+	cfg.MetricExporter = ExporterMemory
+	cfg.TraceExporter = ExporterMemory
+	cfg.LogExporter = ExporterMemory
+
+	providers, err := InitTelemetry(context.Background(), cfg)
+	require.NoError(t, err)
+	defer providers.Shutdown(context.Background())
+	require.NotNil(t, providers.InMemory)
+
+	ctx := context.Background()
+
+	counter, err := providers.MeterProvider.Meter("test").Int64Counter("api_requests_total")
+	require.NoError(t, err)
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String("action_name", "extract")))
+
+	_, span := providers.TracerProvider.Tracer("test").Start(ctx, "spacetraders.request")
+	span.End()
+
+	var record otellog.Record
+	record.SetBody(otellog.StringValue("hello"))
+	providers.LoggerProvider.Logger("test").Emit(ctx, record)
+
+	m, ok, err := providers.InMemory.Metric(ctx, "api_requests_total")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	sum, ok := m.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	assert.Equal(t, int64(1), sum.DataPoints[0].Value)
+	label, _ := sum.DataPoints[0].Attributes.Value("action_name")
+	assert.Equal(t, "extract", label.AsString())
+
+	assert.Len(t, providers.InMemory.SpansNamed("spacetraders.request"), 1)
+
+	logs := providers.InMemory.Logs()
+	require.Len(t, logs, 1)
+	assert.Equal(t, "hello", logs[0].Body().AsString())
+}
+
+func TestInitTelemetry_UnsupportedExporter(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.ServiceName = "test"
+	cfg.TraceExporter = ExporterPrometheus
+
+	_, err := InitTelemetry(context.Background(), cfg)
+	assert.Error(t, err)
+}
@@ -1,38 +1,15 @@
 package models
 
 type ServerStatusResponse struct {
-	Status      string `json:"status"`
-	Version     string `json:"version"`
-	ResetDate   string `json:"resetDate"`
-	Description string `json:"description"`
-	Stats       struct {
-		Agents    int `json:"agents"`
-		Ships     int `json:"ships"`
-		Systems   int `json:"systems"`
-		Waypoints int `json:"waypoints"`
-	} `json:"stats"`
-	Leaderboards struct {
-		MostCredits []struct {
-			AgentSymbol string `json:"agentSymbol"`
-			Credits     int64  `json:"credits"`
-		} `json:"mostCredits"`
-		MostSubmittedCharts []struct {
-			AgentSymbol string `json:"agentSymbol"`
-			ChartCount  int    `json:"chartCount"`
-		} `json:"mostSubmittedCharts"`
-	} `json:"leaderboards"`
-	ServerResets struct {
-		Next      string `json:"next"`
-		Frequency string `json:"frequency"`
-	} `json:"serverResets"`
-	Announcements []struct {
-		Title string `json:"title"`
-		Body  string `json:"body"`
-	} `json:"announcements"`
-	Links []struct {
-		Name string `json:"name"`
-		URL  string `json:"url"`
-	} `json:"links"`
+	Status        string         `json:"status"`
+	Version       string         `json:"version"`
+	ResetDate     string         `json:"resetDate"`
+	Description   string         `json:"description"`
+	Stats         ServerStats    `json:"stats"`
+	Leaderboards  Leaderboards   `json:"leaderboards"`
+	ServerResets  ServerResets   `json:"serverResets"`
+	Announcements []Announcement `json:"announcements"`
+	Links         []Link         `json:"links"`
 }
 
 type RegisterRequest struct {
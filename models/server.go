@@ -0,0 +1,45 @@
+package models
+
+// ServerStats holds the totals reported by the server status
+type ServerStats struct {
+	Agents    int `json:"agents"`
+	Ships     int `json:"ships"`
+	Systems   int `json:"systems"`
+	Waypoints int `json:"waypoints"`
+}
+
+// Leaderboards holds the leaderboards reported by the server status
+type Leaderboards struct {
+	MostCredits         []CreditsLeader `json:"mostCredits"`
+	MostSubmittedCharts []ChartsLeader  `json:"mostSubmittedCharts"`
+}
+
+// CreditsLeader is an entry in the most credits leaderboard
+type CreditsLeader struct {
+	AgentSymbol string `json:"agentSymbol"`
+	Credits     int64  `json:"credits"`
+}
+
+// ChartsLeader is an entry in the most submitted charts leaderboard
+type ChartsLeader struct {
+	AgentSymbol string `json:"agentSymbol"`
+	ChartCount  int    `json:"chartCount"`
+}
+
+// ServerResets describes the server reset schedule
+type ServerResets struct {
+	Next      string `json:"next"`
+	Frequency string `json:"frequency"`
+}
+
+// Announcement is a message published by the server
+type Announcement struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// Link is a link published by the server
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
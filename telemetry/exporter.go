@@ -0,0 +1,18 @@
+package telemetry
+
+// Exporter selects how a telemetry signal leaves the process
+type Exporter string
+
+const (
+	// ExporterOTLP pushes to an OpenTelemetry collector over gRPC (default)
+	ExporterOTLP Exporter = "otlp"
+	// ExporterOTLPHTTP pushes to an OpenTelemetry collector over HTTP/protobuf
+	ExporterOTLPHTTP Exporter = "otlphttp"
+	// ExporterStdout pretty-prints telemetry to standard output
+	ExporterStdout Exporter = "stdout"
+	// ExporterMemory keeps telemetry in memory so tests can assert on it, see InMemoryExporter
+	ExporterMemory Exporter = "memory"
+	// ExporterPrometheus exposes metrics on a pull-based /metrics endpoint.
+	// It only applies to metrics.
+	ExporterPrometheus Exporter = "prometheus"
+)
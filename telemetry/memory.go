@@ -0,0 +1,148 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// InMemoryExporter keeps the spans, metrics and log records emitted by the client in memory,
+// so tests can assert on them. Obtain it from Client.InMemoryTelemetry after enabling
+// ExporterMemory for one or more signals.
+//
+// Example usage:
+//
+//	opts.TelemetryOptions.MetricExporter = telemetry.ExporterMemory
+//	opts.TelemetryOptions.TraceExporter = telemetry.ExporterMemory
+//	c, _ := client.NewClient(opts)
+//
+//	// ... run the bot action under test ...
+//
+//	m, ok, _ := c.InMemoryTelemetry().Metric(ctx, "api_requests_total")
+type InMemoryExporter struct {
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+	logs   *memoryLogExporter
+}
+
+// NewInMemoryExporter creates an empty in-memory exporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{
+		spans:  tracetest.NewInMemoryExporter(),
+		reader: sdkmetric.NewManualReader(),
+		logs:   &memoryLogExporter{},
+	}
+}
+
+// SpanExporter returns the exporter to register with a TracerProvider
+func (m *InMemoryExporter) SpanExporter() sdktrace.SpanExporter {
+	return m.spans
+}
+
+// MetricReader returns the reader to register with a MeterProvider.
+// A reader can only be registered with a single MeterProvider.
+func (m *InMemoryExporter) MetricReader() sdkmetric.Reader {
+	return m.reader
+}
+
+// LogExporter returns the exporter to register with a LoggerProvider
+func (m *InMemoryExporter) LogExporter() sdklog.Exporter {
+	return m.logs
+}
+
+// Spans returns the spans ended so far
+func (m *InMemoryExporter) Spans() tracetest.SpanStubs {
+	return m.spans.GetSpans()
+}
+
+// SpansNamed returns the ended spans with the given name
+func (m *InMemoryExporter) SpansNamed(name string) tracetest.SpanStubs {
+	var matched tracetest.SpanStubs
+	for _, span := range m.spans.GetSpans() {
+		if span.Name == name {
+			matched = append(matched, span)
+		}
+	}
+	return matched
+}
+
+// Metrics collects the current value of every metric
+func (m *InMemoryExporter) Metrics(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	var rm metricdata.ResourceMetrics
+	err := m.reader.Collect(ctx, &rm)
+	return rm, err
+}
+
+// Metric collects the current value of the metric with the given name.
+// The boolean reports whether the metric has been recorded.
+func (m *InMemoryExporter) Metric(ctx context.Context, name string) (metricdata.Metrics, bool, error) {
+	rm, err := m.Metrics(ctx)
+	if err != nil {
+		return metricdata.Metrics{}, false, err
+	}
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, metric := range scope.Metrics {
+			if metric.Name == name {
+				return metric, true, nil
+			}
+		}
+	}
+	return metricdata.Metrics{}, false, nil
+}
+
+// Logs returns the log records emitted so far
+func (m *InMemoryExporter) Logs() []sdklog.Record {
+	return m.logs.records()
+}
+
+// Reset discards recorded spans and log records. Metrics are cumulative and are not reset.
+func (m *InMemoryExporter) Reset() {
+	m.spans.Reset()
+	m.logs.reset()
+}
+
+// memoryLogExporter is an sdklog.Exporter that keeps records in memory
+type memoryLogExporter struct {
+	mu      sync.Mutex
+	entries []sdklog.Record
+}
+
+func (e *memoryLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for i := range records {
+		e.entries = append(e.entries, records[i].Clone())
+	}
+	return nil
+}
+
+func (e *memoryLogExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (e *memoryLogExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+func (e *memoryLogExporter) records() []sdklog.Record {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	records := make([]sdklog.Record, len(e.entries))
+	copy(records, e.entries)
+	return records
+}
+
+func (e *memoryLogExporter) reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.entries = nil
+}